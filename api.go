@@ -5,6 +5,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 func (h *Helicon) hitApi(url string) ([]byte, error) {
@@ -12,24 +13,83 @@ func (h *Helicon) hitApi(url string) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct GET request for url %s", url)
 	}
+	return h.do(req)
+}
+
+// do sends req with common headers, and returns the body if response is 200, [APIError] otherwise.
+func (h *Helicon) do(req *http.Request) ([]byte, error) {
+	resp, err := h.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
+
+// httpResponse is a response read whole, with what [Envelope] needs to know about it.
+type httpResponse struct {
+	body []byte
+	meta ResponseMeta
+}
+
+// roundTrip is [Helicon.do] that keeps the metadata of the response. response is returned along with [APIError] too.
+func (h *Helicon) roundTrip(req *http.Request) (*httpResponse, error) {
 	h.setCommonHeaders(req)
+	url := req.URL.String()
+	startedAt := time.Now()
 	//goland:noinspection GoLinter
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hit %s: %w", url, err)
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.Error("failed to close request body", "error", err)
-		}
-	}(resp.Body)
+	defer closeBody(resp.Body)
 	var respBody []byte
 	respBody, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body from %s: %w", url, err)
+	}
+	response := &httpResponse{body: respBody, meta: newResponseMeta(resp, startedAt)}
+	if resp.StatusCode > 200 {
+		return response, &APIError{StatusCode: resp.StatusCode, URL: url, Body: respBody}
+	}
+	return response, nil
+}
+
+// stream is [Helicon.do] that hands out the body instead of reading it, for responses that are decoded while they
+// are read. caller closes the body. non-200 responses are read whole for [APIError].
+func (h *Helicon) stream(req *http.Request) (io.ReadCloser, error) {
+	h.setCommonHeaders(req)
+	url := req.URL.String()
+	//goland:noinspection GoLinter
+	resp, err := http.DefaultClient.Do(req) //nolint:bodyclose // closed by caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to hit %s: %w", url, err)
+	}
 	if resp.StatusCode > 200 {
-		return nil, fmt.Errorf("unexpected status code %d from with body %s", resp.StatusCode, respBody)
+		defer closeBody(resp.Body)
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body from %s: %w", url, err)
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, URL: url, Body: respBody}
 	}
-	return respBody, nil
+	return resp.Body, nil
+}
+
+func closeBody(body io.Closer) {
+	if err := body.Close(); err != nil {
+		slog.Error("failed to close request body", "error", err)
+	}
+}
+
+// APIError is returned when X responds with anything other than 200.
+type APIError struct {
+	StatusCode int
+	URL        string
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from %s with body %s", e.StatusCode, e.URL, e.Body)
 }
 
 // SetCommonHeaders including auth headers.
@@ -0,0 +1,287 @@
+package helicon
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RawArticle is `article_results.result`, an X Article. ContentState is there only if field toggle
+// WithArticleRichContentState is set, see [NewTweetDetailFieldToggles].
+type RawArticle struct {
+	RestId        string            `json:"rest_id"`
+	Id            string            `json:"id,omitempty"`
+	Title         string            `json:"title"`
+	PreviewText   string            `json:"preview_text,omitempty"`
+	CoverMedia    *RawArticleMedia  `json:"cover_media,omitempty"`
+	ContentState  ArticleContent    `json:"content_state,omitzero"`
+	MediaEntities []RawArticleMedia `json:"media_entities,omitempty"`
+	Metadata      struct {
+		FirstPublishedAtSecs int64 `json:"first_published_at_secs,omitempty"`
+	} `json:"metadata"`
+}
+
+type RawArticleMedia struct {
+	Id        string `json:"id,omitempty"`
+	MediaId   string `json:"media_id"`
+	MediaKey  string `json:"media_key"`
+	MediaInfo struct {
+		Typename          string `json:"__typename"`
+		OriginalImgUrl    string `json:"original_img_url"`
+		OriginalImgWidth  int    `json:"original_img_width,omitempty"`
+		OriginalImgHeight int    `json:"original_img_height,omitempty"`
+	} `json:"media_info"`
+}
+
+// ArticleContent is content state of Draft.js, the editor X Articles are written in. offsets and lengths are of UTF-16
+// code units, JavaScript strings.
+type ArticleContent struct {
+	Blocks    []ArticleBlock   `json:"blocks"`
+	EntityMap ArticleEntityMap `json:"entityMap"`
+}
+
+// ArticleBlockType is type of a Draft.js block.
+type ArticleBlockType string
+
+const (
+	ArticleParagraph     ArticleBlockType = "unstyled"
+	ArticleHeaderOne     ArticleBlockType = "header-one"
+	ArticleHeaderTwo     ArticleBlockType = "header-two"
+	ArticleHeaderThree   ArticleBlockType = "header-three"
+	ArticleBlockquote    ArticleBlockType = "blockquote"
+	ArticleCodeBlock     ArticleBlockType = "code-block"
+	ArticleUnorderedList ArticleBlockType = "unordered-list-item"
+	ArticleOrderedList   ArticleBlockType = "ordered-list-item"
+	// ArticleAtomic is a block that is an entity, image, divider or embedded tweet, text of it is a placeholder.
+	ArticleAtomic ArticleBlockType = "atomic"
+)
+
+// ArticleBlock is a paragraph, header, list item... of an article.
+type ArticleBlock struct {
+	Key               string           `json:"key"`
+	Type              ArticleBlockType `json:"type"`
+	Text              string           `json:"text"`
+	Depth             int              `json:"depth,omitempty"`
+	InlineStyleRanges []struct {
+		Offset int `json:"offset"`
+		Length int `json:"length"`
+		// "Bold", "Italic", "Strikethrough".
+		Style string `json:"style"`
+	} `json:"inlineStyleRanges"`
+	EntityRanges []struct {
+		// key of [ArticleContent.EntityMap].
+		Key    int `json:"key"`
+		Offset int `json:"offset"`
+		Length int `json:"length"`
+	} `json:"entityRanges"`
+}
+
+// ArticleEntityType is type of a Draft.js entity.
+type ArticleEntityType string
+
+const (
+	ArticleLink    ArticleEntityType = "LINK"
+	ArticleMedia   ArticleEntityType = "MEDIA"
+	ArticleDivider ArticleEntityType = "DIVIDER"
+	ArticleTweet   ArticleEntityType = "TWEET"
+	// ArticleMarkdown is Markdown X renders itself, code blocks mostly.
+	ArticleMarkdown ArticleEntityType = "MARKDOWN"
+)
+
+type ArticleEntity struct {
+	Type       ArticleEntityType `json:"type"`
+	Mutability string            `json:"mutability,omitempty"`
+	Data       struct {
+		Url        string `json:"url,omitempty"`
+		TweetId    string `json:"tweetId,omitempty"`
+		Markdown   string `json:"markdown,omitempty"`
+		Caption    string `json:"caption,omitempty"`
+		MediaItems []struct {
+			MediaId       string `json:"mediaId"`
+			MediaCategory string `json:"mediaCategory,omitempty"`
+		} `json:"mediaItems,omitempty"`
+	} `json:"data"`
+}
+
+// ArticleEntityMap is entities of an article by key. X sends it as a list of key and value pairs, Draft.js as an
+// object, both are read. it is marshalled as an object.
+type ArticleEntityMap map[string]ArticleEntity
+
+func (m *ArticleEntityMap) UnmarshalJSON(data []byte) error {
+	var object map[string]ArticleEntity
+	if err := json.Unmarshal(data, &object); err == nil {
+		*m = object
+		return nil
+	}
+	var pairs []struct {
+		Key   string        `json:"key"`
+		Value ArticleEntity `json:"value"`
+	}
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return fmt.Errorf("entity map: %w", err)
+	}
+	*m = make(ArticleEntityMap, len(pairs))
+	for _, pair := range pairs {
+		(*m)[pair.Key] = pair.Value
+	}
+	return nil
+}
+
+// Article is an X Article, see [Article.Markdown].
+type Article struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	PreviewText string    `json:"preview_text,omitempty"`
+	CoverURL    string    `json:"cover_url,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitzero"`
+	// empty if field toggle WithArticleRichContentState is not set.
+	Content ArticleContent `json:"content,omitzero"`
+	// original image URLs of media in Content by media ID.
+	MediaURLs map[string]string `json:"media_urls,omitempty"`
+}
+
+// Markdown is the title and content of the article. images and embedded tweets are put where they are in the
+// article, links to tweets as Markdown can't embed them.
+func (a *Article) Markdown() string {
+	parts := []string{"# " + escapeText(a.Title, formatMarkdown)}
+	var previous ArticleBlockType
+	number := 0
+	for _, block := range a.Content.Blocks {
+		if block.Type == ArticleOrderedList {
+			number++
+		} else {
+			number = 0
+		}
+		rendered := a.blockMarkdown(block, number)
+		if rendered == "" {
+			continue
+		}
+		// items of a list are not paragraphs of their own.
+		if block.Type == previous && (block.Type == ArticleOrderedList || block.Type == ArticleUnorderedList) {
+			parts[len(parts)-1] += "\n" + rendered
+		} else {
+			parts = append(parts, rendered)
+		}
+		previous = block.Type
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (a *Article) blockMarkdown(block ArticleBlock, number int) string {
+	switch block.Type {
+	case ArticleAtomic:
+		var rendered []string
+		for _, r := range block.EntityRanges {
+			if entity := a.entityMarkdown(strconv.Itoa(r.Key)); entity != "" {
+				rendered = append(rendered, entity)
+			}
+		}
+		return strings.Join(rendered, "\n\n")
+	case ArticleCodeBlock:
+		return "```\n" + block.Text + "\n```"
+	}
+	text := a.inlineMarkdown(block)
+	if text == "" {
+		return ""
+	}
+	indent := strings.Repeat("  ", block.Depth)
+	switch block.Type {
+	case ArticleHeaderOne:
+		// title is the only top level header.
+		return "## " + text
+	case ArticleHeaderTwo:
+		return "### " + text
+	case ArticleHeaderThree:
+		return "#### " + text
+	case ArticleBlockquote:
+		return "> " + text
+	case ArticleUnorderedList:
+		return indent + "- " + text
+	case ArticleOrderedList:
+		return indent + strconv.Itoa(number) + ". " + text
+	}
+	return text
+}
+
+func (a *Article) inlineMarkdown(block ArticleBlock) string {
+	text := []rune(block.Text)
+	indices := func(offset, length int) [2]int {
+		return [2]int{runeIndex(text, offset), runeIndex(text, offset+length)}
+	}
+	var entities []textEntity
+	for _, r := range block.EntityRanges {
+		entity := a.Content.EntityMap[strconv.Itoa(r.Key)]
+		if entity.Type != ArticleLink {
+			continue
+		}
+		i := indices(r.Offset, r.Length)
+		if i[0] >= i[1] {
+			continue
+		}
+		display := string(text[i[0]:i[1]])
+		entities = append(entities, textEntity{i, display, entity.Data.Url, display})
+	}
+	var inserts []textInsert
+	for _, r := range block.InlineStyleRanges {
+		i := indices(r.Offset, r.Length)
+		if i[0] >= i[1] {
+			continue
+		}
+		inserts = append(inserts, styleInserts(i[0], i[1], r.Style)...)
+	}
+	return renderText(text, 0, len(text), entities, inserts, formatMarkdown)
+}
+
+func (a *Article) entityMarkdown(key string) string {
+	entity, ok := a.Content.EntityMap[key]
+	if !ok {
+		return ""
+	}
+	switch entity.Type {
+	case ArticleDivider:
+		return "---"
+	case ArticleMarkdown:
+		return entity.Data.Markdown
+	case ArticleTweet:
+		link := "https://x.com/i/status/" + url.PathEscape(entity.Data.TweetId)
+		return "[" + link + "](" + link + ")"
+	case ArticleMedia:
+		var images []string
+		for _, item := range entity.Data.MediaItems {
+			if src := a.MediaURLs[item.MediaId]; src != "" {
+				images = append(images, "!["+escapeText(entity.Data.Caption, formatMarkdown)+"]("+markdownURL.Replace(src)+")")
+			}
+		}
+		return strings.Join(images, "\n\n")
+	}
+	return ""
+}
+
+func (raw *RawArticle) article() *Article {
+	article := &Article{
+		ID:          cmp.Or(raw.RestId, raw.Id),
+		Title:       raw.Title,
+		PreviewText: raw.PreviewText,
+		Content:     raw.ContentState,
+	}
+	if raw.CoverMedia != nil {
+		article.CoverURL = raw.CoverMedia.MediaInfo.OriginalImgUrl
+	}
+	if secs := raw.Metadata.FirstPublishedAtSecs; secs != 0 {
+		article.PublishedAt = time.Unix(secs, 0)
+	}
+	for _, media := range raw.MediaEntities {
+		if media.MediaInfo.OriginalImgUrl == "" {
+			continue
+		}
+		if article.MediaURLs == nil {
+			article.MediaURLs = map[string]string{}
+		}
+		article.MediaURLs[media.MediaId] = media.MediaInfo.OriginalImgUrl
+	}
+	return article
+}
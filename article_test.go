@@ -0,0 +1,75 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"testing"
+	"time"
+)
+
+func TestExtractTweets_Article(t *testing.T) {
+	t.Parallel()
+	// X sends entity map as a list of key and value pairs, offsets are of UTF-16 code units, 🐹 is two of them.
+	set, err := helicon.ExtractTweets([]byte(`{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1",
+		"legacy":{"id_str":"1","full_text":"https://t.co/article"},
+		"article":{"article_results":{"result":{"rest_id":"42","title":"Hamsters & Go","preview_text":"Why hamsters",
+			"cover_media":{"media_id":"5","media_info":{"__typename":"ApiImage","original_img_url":"https://pbs.twimg.com/media/GCover.jpg"}},
+			"media_entities":[{"media_id":"6","media_key":"3_6","media_info":{"__typename":"ApiImage","original_img_url":"https://pbs.twimg.com/media/GBody.jpg"}}],
+			"metadata":{"first_published_at_secs":1717243200},
+			"content_state":{"blocks":[
+				{"key":"a","type":"header-one","text":"Intro","inlineStyleRanges":[],"entityRanges":[]},
+				{"key":"b","type":"unstyled","text":"🐹 are *fast*, see the docs.","inlineStyleRanges":[{"offset":7,"length":6,"style":"Bold"}],"entityRanges":[{"key":0,"offset":23,"length":4}]},
+				{"key":"c","type":"unstyled","text":"","inlineStyleRanges":[],"entityRanges":[]},
+				{"key":"d","type":"unordered-list-item","text":"one","inlineStyleRanges":[],"entityRanges":[]},
+				{"key":"e","type":"unordered-list-item","text":"two","inlineStyleRanges":[],"entityRanges":[]},
+				{"key":"f","type":"ordered-list-item","text":"first","inlineStyleRanges":[],"entityRanges":[]},
+				{"key":"g","type":"ordered-list-item","text":"second","inlineStyleRanges":[],"entityRanges":[]},
+				{"key":"h","type":"atomic","text":" ","inlineStyleRanges":[],"entityRanges":[{"key":1,"offset":0,"length":1}]},
+				{"key":"i","type":"atomic","text":" ","inlineStyleRanges":[],"entityRanges":[{"key":2,"offset":0,"length":1}]},
+				{"key":"j","type":"blockquote","text":"quoted","inlineStyleRanges":[{"offset":0,"length":6,"style":"Italic"}],"entityRanges":[]},
+				{"key":"k","type":"atomic","text":" ","inlineStyleRanges":[],"entityRanges":[{"key":3,"offset":0,"length":1}]}],
+			"entityMap":[
+				{"key":"0","value":{"type":"LINK","mutability":"MUTABLE","data":{"url":"https://go.dev/doc/"}}},
+				{"key":"1","value":{"type":"MEDIA","mutability":"Immutable","data":{"caption":"a hamster","mediaItems":[{"mediaId":"6","mediaCategory":"DraftTweetImage"}]}}},
+				{"key":"2","value":{"type":"DIVIDER","mutability":"Immutable","data":{}}},
+				{"key":"3","value":{"type":"TWEET","mutability":"Immutable","data":{"tweetId":"1800000000000000001"}}}]}}}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	article := set.Tweet("1").Article
+	if article == nil || article.ID != "42" || article.CoverURL != "https://pbs.twimg.com/media/GCover.jpg" || !article.PublishedAt.Equal(time.Unix(1717243200, 0)) {
+		t.Fatalf("unexpected article %+v", article)
+	}
+	want := "# Hamsters & Go\n\n" +
+		"## Intro\n\n" +
+		"🐹 are **\\*fast\\***, see the [docs](https://go.dev/doc/).\n\n" +
+		"- one\n- two\n\n" +
+		"1. first\n2. second\n\n" +
+		"![a hamster](https://pbs.twimg.com/media/GBody.jpg)\n\n" +
+		"---\n\n" +
+		"> _quoted_\n\n" +
+		"[https://x.com/i/status/1800000000000000001](https://x.com/i/status/1800000000000000001)"
+	if got := article.Markdown(); got != want {
+		t.Errorf("markdown\n got: %s\nwant: %s", got, want)
+	}
+
+	var object helicon.ArticleContent
+	if err := object.EntityMap.UnmarshalJSON([]byte(`{"0":{"type":"LINK","data":{"url":"https://go.dev"}}}`)); err != nil || object.EntityMap["0"].Data.Url != "https://go.dev" {
+		t.Errorf("Draft.js entity maps are objects, got %+v, %v", object.EntityMap, err)
+	}
+}
+
+func TestExtractTweets_ArticleBadRanges(t *testing.T) {
+	t.Parallel()
+	set, err := helicon.ExtractTweets([]byte(`{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1",
+		"legacy":{"id_str":"1","full_text":"https://t.co/article"},
+		"article":{"article_results":{"result":{"rest_id":"42","title":"Ranges",
+			"content_state":{"blocks":[
+				{"key":"a","type":"unstyled","text":"see the docs","inlineStyleRanges":[{"offset":8,"length":-4,"style":"Bold"}],"entityRanges":[{"key":0,"offset":8,"length":-4}]}],
+			"entityMap":[{"key":"0","value":{"type":"LINK","mutability":"MUTABLE","data":{"url":"https://go.dev/doc/"}}}]}}}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := set.Tweet("1").Article.Markdown(), "# Ranges\n\nsee the docs"; got != want {
+		t.Errorf("ranges that end before they start must be skipped\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -1,13 +1,60 @@
 package helicon
 
 import (
+	"errors"
 	"fmt"
+	"github.com/zalando/go-keyring"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 )
 
+// DefaultMaxLoginAttempts is used when [Helicon.MaxLoginAttempts] is not set.
+const DefaultMaxLoginAttempts = 3
+
+// Login runs the whole login flow and saves the tokens to keyring.
+//
+// Flow is retried only when it finishes without session cookies ([ErrLoginNotCompleted]), up to [Helicon.MaxLoginAttempts] times.
+// Anything that needs a human (arkose, locked or suspended account, wrong password, see [LoginBlockedError]) is returned immediately,
+// retrying those is how you get your account locked.
 func (h *Helicon) Login() error {
+	if err := h.retryLogin(h.login); err != nil {
+		return err
+	}
+	if err := h.SaveTokensToKeyring(); err != nil {
+		return fmt.Errorf("failed to save the token to keyring: %w", err)
+	}
+	_ = h.LoadTokensFromKeyring()
+
+	return nil
+}
+
+// retryLogin runs login until it goes through, up to [Helicon.MaxLoginAttempts] times. only [ErrLoginNotCompleted] is
+// retried.
+func (h *Helicon) retryLogin(login func() error) error {
+	attempts := h.MaxLoginAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxLoginAttempts
+	}
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = login(); err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrLoginNotCompleted) {
+			return err
+		}
+		slog.Info("we have to execute another login flow, current attempt was successful but logged us out...", "attempt", attempt, "max_attempts", attempts)
+	}
+	return fmt.Errorf("giving up after %d login attempts: %w", attempts, err)
+}
+
+func (h *Helicon) login() error {
 	var flow *LoginFlow
 	var err error
 	if flow, err = h.StartLoginFlow(); err != nil {
@@ -19,11 +66,6 @@ func (h *Helicon) Login() error {
 	if err = flow.SubmitUsernameAndPassword(h); err != nil {
 		return fmt.Errorf("failed to submit username: %w", err)
 	}
-	if err = h.SaveTokensToKeyring(); err != nil {
-		return fmt.Errorf("failed to save the token to keyring: %w", err)
-	}
-	_ = h.LoadTokensFromKeyring()
-
 	return nil
 }
 
@@ -73,3 +115,91 @@ func (h *Helicon) SetDefaultUserAgent(userAgent *string) {
 		h.UserAgent = *userAgent
 	}
 }
+
+// Logout invalidates the auth_token on X's side, deletes the tokens from [Helicon.TokenStore] and clears [Helicon.Cookies].
+//
+// If X can't be reached, nothing is deleted locally so you can try again, tokens that are still valid shouldn't be
+// forgotten before they are revoked. If X says tokens are already invalid, they are deleted anyway.
+func (h *Helicon) Logout() error {
+	if h.Cookies.AuthToken.Value == "" {
+		if err := h.LoadTokensFromKeyring(); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("failed to load tokens to revoke: %w", err)
+		}
+	}
+	if h.Cookies.AuthToken.Value != "" {
+		if err := h.revokeSession(); err != nil {
+			return err
+		}
+	}
+	if err := h.DeleteTokensFromKeyring(); err != nil {
+		return err
+	}
+	h.Cookies = TwitterCookies{}
+	h.UserID = ""
+	return nil
+}
+
+// LogoutAll runs [Helicon.Logout] for every profile in [Helicon.TokenStore], and for [TwitterCredentials.Username] of
+// h even if the store does not list it, using user agent of h.
+// Every profile is attempted even if some fail, failures are joined into the returned error.
+func (h *Helicon) LogoutAll() error {
+	profiles, err := h.tokenStore().List()
+	if err != nil {
+		return err
+	}
+	// keyring profiles saved before the index existed are not listed until they are loaded.
+	if h.Credentials.Username != "" && !slices.Contains(profiles, h.Credentials.Username) {
+		profiles = append(profiles, h.Credentials.Username)
+	}
+	var errs []error
+	for _, username := range profiles {
+		profile := Helicon{
+			Credentials: TwitterCredentials{Username: username},
+			UserAgent:   h.UserAgent,
+			TokenStore:  h.TokenStore,
+		}
+		if err := profile.Logout(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to logout %s: %w", username, err))
+			continue
+		}
+		if username == h.Credentials.Username {
+			h.Cookies = TwitterCookies{}
+			h.UserID = ""
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// revokeSession is what "Log out" button does in browser.
+func (h *Helicon) revokeSession() error {
+	form := url.Values{"redirectAfterLogout": {"https://x.com/account/switch"}}
+	req, err := http.NewRequest(http.MethodPost, "https://x.com/i/api/1.1/account/logout.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to construct logout request: %w", err)
+	}
+	h.setCommonHeaders(req)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to hit %s: %w", req.URL.String(), err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.Error("failed to close request body", "error", err)
+		}
+	}(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read logout response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		slog.Info("session was already invalid on X's side", "username", h.Credentials.Username, "status", resp.StatusCode)
+		return nil
+	default:
+		return fmt.Errorf("unexpected status code %d from %s with body %s", resp.StatusCode, req.URL.String(), respBody)
+	}
+}
@@ -1,8 +1,13 @@
 package helicon_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/caner-cetin/helicon"
+	"github.com/zalando/go-keyring"
+	"maps"
+	"net/http"
+	"slices"
 	"testing"
 	"time"
 )
@@ -17,3 +22,101 @@ func TestHelicon_Authenticate(t *testing.T) {
 	fmt.Printf("csrf token expired: %v \n", client.Cookies.AuthToken.Expires.Before(time.Now()))
 	// or do whatever you want with it
 }
+
+// memoryStore is a [helicon.TokenStore] that keeps tokens in a map.
+type memoryStore map[string]string
+
+func (s memoryStore) Set(username string, secret string) error {
+	s[username] = secret
+	return nil
+}
+
+func (s memoryStore) Get(username string) (string, error) {
+	secret, ok := s[username]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s memoryStore) Delete(username string) error {
+	delete(s, username)
+	return nil
+}
+
+func (s memoryStore) List() ([]string, error) {
+	return slices.Sorted(maps.Keys(s)), nil
+}
+
+func TestHelicon_Logout(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		// tokens must be forgotten only if X no longer accepts them.
+		deleted bool
+	}{
+		{"revoked", http.StatusOK, true},
+		{"already invalid", http.StatusUnauthorized, true},
+		{"unreachable", http.StatusInternalServerError, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests []*http.Request
+			helicon.StubTransport(t, func(r *http.Request) (*http.Response, error) {
+				requests = append(requests, r)
+				return helicon.StubResponse(r, c.status, `{}`), nil
+			})
+			store := memoryStore{}
+			client := newSessionClient()
+			client.TokenStore = store
+			if err := client.SaveTokensToKeyring(); err != nil {
+				t.Fatal(err)
+			}
+			client.Cookies = helicon.TwitterCookies{}
+
+			err := client.Logout()
+			if len(requests) != 1 {
+				t.Fatalf("expected a single logout request, got %d", len(requests))
+			}
+			if r := requests[0]; r.Method != http.MethodPost || r.URL.Path != "/i/api/1.1/account/logout.json" || r.Header.Get("Cookie") != "auth_token=auth; ct0=csrf" {
+				t.Errorf("unexpected logout request %s %s with cookies %q", r.Method, r.URL, r.Header.Get("Cookie"))
+			}
+			_, stored := store["someone"]
+			if c.deleted {
+				if err != nil || stored || client.Cookies.AuthToken.Value != "" || client.UserID != "" {
+					t.Fatalf("expected tokens to be forgotten, got %v, stored %v, cookies %+v", err, stored, client.Cookies)
+				}
+				return
+			}
+			if err == nil || !stored {
+				t.Fatalf("expected an error and tokens to be kept, got %v, stored %v", err, stored)
+			}
+		})
+	}
+}
+
+func TestHelicon_LogoutAll_Unindexed(t *testing.T) {
+	keyring.MockInit()
+	requests := 0
+	helicon.StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		requests++
+		return helicon.StubResponse(r, http.StatusOK, `{}`), nil
+	})
+	client := newSessionClient()
+	if err := client.SaveTokensToKeyring(); err != nil {
+		t.Fatal(err)
+	}
+	// tokens saved before the profile index existed.
+	if err := keyring.Delete("helicon", "\x1Fprofiles"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.LogoutAll(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected unindexed profile to be logged out, got %d requests", requests)
+	}
+	if _, err := keyring.Get("helicon", "someone"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Fatalf("expected tokens to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package helicon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// BirdwatchPivot is `birdwatch_pivot` of a tweet, the Community Note shown under it. Birdwatch is what Community
+// Notes were called before, GraphQL still calls them that.
+type BirdwatchPivot struct {
+	Title      string `json:"title"`
+	ShortTitle string `json:"shorttitle,omitempty"`
+	// text of the note, with its links.
+	Subtitle       RichText `json:"subtitle"`
+	Footer         RichText `json:"footer"`
+	DestinationUrl string   `json:"destinationUrl"`
+	CallToAction   *struct {
+		Prompt         string `json:"prompt"`
+		Title          string `json:"title"`
+		DestinationUrl string `json:"destinationUrl"`
+	} `json:"callToAction,omitempty"`
+	Note struct {
+		RestId                      string `json:"rest_id"`
+		Language                    string `json:"language,omitempty"`
+		IsCommunityNoteTranslatable bool   `json:"is_community_note_translatable,omitempty"`
+	} `json:"note"`
+	VisualStyle string `json:"visualStyle,omitempty"`
+	IconType    string `json:"iconType,omitempty"`
+}
+
+// CommunityNoteSummary is the Community Note shown under a tweet, see [Helicon.GetCommunityNotes] for the rest of it.
+type CommunityNoteSummary struct {
+	NoteID string `json:"note_id"`
+	// "Readers added context they thought people might want to know".
+	Title string   `json:"title"`
+	Text  RichText `json:"text"`
+	URL   string   `json:"url,omitempty"`
+}
+
+func (pivot *BirdwatchPivot) summary() *CommunityNoteSummary {
+	return &CommunityNoteSummary{
+		NoteID: pivot.Note.RestId,
+		Title:  pivot.Title,
+		Text:   pivot.Subtitle,
+		URL:    pivot.DestinationUrl,
+	}
+}
+
+// NoteRatingStatus is where a note stands with raters.
+type NoteRatingStatus string
+
+const (
+	// NoteHelpful notes are shown under the tweet.
+	NoteHelpful      NoteRatingStatus = "CurrentlyRatedHelpful"
+	NoteNotHelpful   NoteRatingStatus = "CurrentlyRatedNotHelpful"
+	NoteNeedsRatings NoteRatingStatus = "NeedsMoreRatings"
+)
+
+// NoteClassification is what the author of a note thinks of the tweet.
+type NoteClassification string
+
+const (
+	NoteMisleading    NoteClassification = "MisinformedOrPotentiallyMisleading"
+	NoteNotMisleading NoteClassification = "NotMisleading"
+)
+
+// RawCommunityNote is a note as BirdwatchFetchNotes and BirdwatchFetchOneNote return it.
+type RawCommunityNote struct {
+	RestId string `json:"rest_id"`
+	// unix milliseconds.
+	CreatedAt      int64              `json:"created_at"`
+	Classification NoteClassification `json:"classification"`
+	DataV1         struct {
+		Summary            RichText `json:"summary"`
+		MisleadingTags     []string `json:"misleading_tags,omitempty"`
+		NotMisleadingTags  []string `json:"not_misleading_tags,omitempty"`
+		TrustworthySources bool     `json:"trustworthy_sources"`
+	} `json:"data_v1"`
+	RatingStatus     NoteRatingStatus `json:"rating_status"`
+	HelpfulTags      []string         `json:"helpful_tags,omitempty"`
+	NotHelpfulTags   []string         `json:"not_helpful_tags,omitempty"`
+	DecidedBy        string           `json:"decided_by,omitempty"`
+	Language         string           `json:"language,omitempty"`
+	BirdwatchProfile struct {
+		Alias string `json:"alias"`
+	} `json:"birdwatch_profile"`
+	TweetResults TweetResults `json:"tweet_results,omitzero"`
+}
+
+// CommunityNote is a Community Note of a tweet, shown or not.
+type CommunityNote struct {
+	ID             string             `json:"id"`
+	TweetID        string             `json:"tweet_id,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	Classification NoteClassification `json:"classification"`
+	Text           RichText           `json:"text"`
+	// links in Text.
+	Sources []string `json:"sources,omitempty"`
+	// author says the sources are trustworthy.
+	TrustworthySources bool `json:"trustworthy_sources"`
+	// why the author thinks the tweet is or is not misleading, "factual_error", "missing_important_context"...
+	Tags         []string         `json:"tags,omitempty"`
+	RatingStatus NoteRatingStatus `json:"rating_status"`
+	// why raters found the note helpful, "GoodSources", "Clear"..., or not helpful, "SourcesMissingOrUnreliable"...
+	HelpfulTags    []string `json:"helpful_tags,omitempty"`
+	NotHelpfulTags []string `json:"not_helpful_tags,omitempty"`
+	// scoring model that decided RatingStatus.
+	DecidedBy   string `json:"decided_by,omitempty"`
+	Language    string `json:"language,omitempty"`
+	AuthorAlias string `json:"author_alias,omitempty"`
+}
+
+// Helpful reports whether the note is shown under the tweet.
+func (n *CommunityNote) Helpful() bool {
+	return n.RatingStatus == NoteHelpful
+}
+
+func (raw *RawCommunityNote) note() *CommunityNote {
+	note := &CommunityNote{
+		ID:                 raw.RestId,
+		Classification:     raw.Classification,
+		Text:               raw.DataV1.Summary,
+		TrustworthySources: raw.DataV1.TrustworthySources,
+		Tags:               slices.Concat(raw.DataV1.MisleadingTags, raw.DataV1.NotMisleadingTags),
+		RatingStatus:       raw.RatingStatus,
+		HelpfulTags:        raw.HelpfulTags,
+		NotHelpfulTags:     raw.NotHelpfulTags,
+		DecidedBy:          raw.DecidedBy,
+		Language:           raw.Language,
+		AuthorAlias:        raw.BirdwatchProfile.Alias,
+	}
+	if raw.CreatedAt != 0 {
+		note.CreatedAt = time.UnixMilli(raw.CreatedAt)
+	}
+	if result := raw.TweetResults.Result; result != nil && result.Tweet != nil {
+		note.TweetID = result.Tweet.id()
+	}
+	for _, entity := range raw.DataV1.Summary.Entities {
+		if entity.Ref.Url != "" {
+			note.Sources = append(note.Sources, entity.Ref.Url)
+		}
+	}
+	return note
+}
+
+// CommunityNotes are every note written for a tweet, including the ones that are not shown.
+type CommunityNotes struct {
+	// notes that say the tweet is misleading, the one shown under the tweet is one of these.
+	Misleading []*CommunityNote `json:"misleading"`
+	// notes that say the tweet is fine as it is, these are never shown.
+	NotMisleading []*CommunityNote `json:"not_misleading"`
+}
+
+// Shown returns the notes that are shown under the tweet.
+func (n *CommunityNotes) Shown() []*CommunityNote {
+	var shown []*CommunityNote
+	for _, note := range n.Misleading {
+		if note.Helpful() {
+			shown = append(shown, note)
+		}
+	}
+	return shown
+}
+
+type communityNotesData struct {
+	TweetResultByRestId struct {
+		Result struct {
+			MisleadingBirdwatchNotes struct {
+				Notes []RawCommunityNote `json:"notes"`
+			} `json:"misleading_birdwatch_notes"`
+			NotMisleadingBirdwatchNotes struct {
+				Notes []RawCommunityNote `json:"notes"`
+			} `json:"not_misleading_birdwatch_notes"`
+		} `json:"result"`
+	} `json:"tweet_result_by_rest_id"`
+}
+
+func (data *communityNotesData) notes(tweetID string) *CommunityNotes {
+	notes := &CommunityNotes{}
+	result := data.TweetResultByRestId.Result
+	for i := range result.MisleadingBirdwatchNotes.Notes {
+		notes.Misleading = append(notes.Misleading, result.MisleadingBirdwatchNotes.Notes[i].note())
+	}
+	for i := range result.NotMisleadingBirdwatchNotes.Notes {
+		notes.NotMisleading = append(notes.NotMisleading, result.NotMisleadingBirdwatchNotes.Notes[i].note())
+	}
+	for _, note := range slices.Concat(notes.Misleading, notes.NotMisleading) {
+		if note.TweetID == "" {
+			note.TweetID = tweetID
+		}
+	}
+	return notes
+}
+
+// GetCommunityNotes fetches every Community Note of tweetID through BirdwatchFetchNotes. query ID is discovered
+// through [QueryRegistry], there is no fallback.
+func (h *Helicon) GetCommunityNotes(tweetID string, opts ...ExecuteOption) (*CommunityNotes, error) {
+	var data communityNotesData
+	op := Operation{Name: "BirdwatchFetchNotes", Type: OperationQuery, Variables: map[string]any{"tweet_id": tweetID}}
+	if err := h.Execute(context.Background(), op, &data, opts...); err != nil {
+		return nil, err
+	}
+	return data.notes(tweetID), nil
+}
+
+// GetCommunityNote fetches a single note through BirdwatchFetchOneNote, ID of the one shown under a tweet is
+// [CommunityNoteSummary.NoteID].
+func (h *Helicon) GetCommunityNote(noteID string, opts ...ExecuteOption) (*CommunityNote, error) {
+	var data struct {
+		BirdwatchNoteByRestId *RawCommunityNote `json:"birdwatch_note_by_rest_id"`
+	}
+	op := Operation{Name: "BirdwatchFetchOneNote", Type: OperationQuery, Variables: map[string]any{"note_id": noteID}}
+	if err := h.Execute(context.Background(), op, &data, opts...); err != nil {
+		return nil, err
+	}
+	if data.BirdwatchNoteByRestId == nil {
+		return nil, fmt.Errorf("community note %s not found", noteID)
+	}
+	return data.BirdwatchNoteByRestId.note(), nil
+}
@@ -0,0 +1,69 @@
+package helicon
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExtractTweets_CommunityNote(t *testing.T) {
+	t.Parallel()
+	set, err := ExtractTweets([]byte(`{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1","has_birdwatch_notes":true,
+		"legacy":{"id_str":"1","full_text":"the earth is flat"},
+		"birdwatch_pivot":{"title":"Readers added context they thought people might want to know","shorttitle":"Readers added context",
+			"destinationUrl":"https://x.com/i/birdwatch/n/99",
+			"subtitle":{"text":"It is not. https://t.co/nasa","entities":[{"fromIndex":11,"toIndex":28,"ref":{"type":"TimelineUrl","url":"https://t.co/nasa","urlType":"ExternalUrl"}}]},
+			"footer":{"text":"Context is written by people who use X."},
+			"note":{"rest_id":"99","language":"en"},"visualStyle":"Default","iconType":"BirdwatchV1Icon"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweet := set.Tweet("1")
+	if !tweet.HasCommunityNotes || tweet.CommunityNote == nil {
+		t.Fatalf("expected a community note, got %+v", tweet)
+	}
+	note := tweet.CommunityNote
+	if note.NoteID != "99" || note.URL != "https://x.com/i/birdwatch/n/99" || note.Text.Markdown() != "It is not. [https://t.co/nasa](https://t.co/nasa)" {
+		t.Errorf("unexpected note %+v", note)
+	}
+}
+
+func TestCommunityNotesData(t *testing.T) {
+	t.Parallel()
+	var data communityNotesData
+	err := json.Unmarshal([]byte(`{"tweet_result_by_rest_id":{"result":{
+		"misleading_birdwatch_notes":{"notes":[
+			{"rest_id":"99","created_at":1717243200000,"classification":"MisinformedOrPotentiallyMisleading","rating_status":"CurrentlyRatedHelpful",
+				"data_v1":{"summary":{"text":"It is not. https://t.co/nasa","entities":[{"fromIndex":11,"toIndex":28,"ref":{"type":"TimelineUrl","url":"https://t.co/nasa"}}]},
+					"misleading_tags":["factual_error"],"trustworthy_sources":true},
+				"helpful_tags":["GoodSources","Clear"],"decided_by":"CoreModel (v1.1)","language":"en","birdwatch_profile":{"alias":"Curious Hamster"}},
+			{"rest_id":"98","created_at":1717243100000,"classification":"MisinformedOrPotentiallyMisleading","rating_status":"NeedsMoreRatings",
+				"data_v1":{"summary":{"text":"Flat is relative."},"misleading_tags":["other"]}}]},
+		"not_misleading_birdwatch_notes":{"notes":[
+			{"rest_id":"97","classification":"NotMisleading","rating_status":"CurrentlyRatedNotHelpful","data_v1":{"summary":{"text":"NNN"},"not_misleading_tags":["personal_opinion"]},
+				"not_helpful_tags":["NoteNotNeeded"],"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1"}}}]}}}}`), &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := data.notes("1")
+	if len(notes.Misleading) != 2 || len(notes.NotMisleading) != 1 {
+		t.Fatalf("expected 2 misleading and 1 not misleading notes, got %+v", notes)
+	}
+	shown := notes.Shown()
+	if len(shown) != 1 || shown[0].ID != "99" {
+		t.Fatalf("expected only the helpful note to be shown, got %+v", shown)
+	}
+	note := shown[0]
+	if note.TweetID != "1" || !note.CreatedAt.Equal(time.UnixMilli(1717243200000)) || !note.TrustworthySources || note.AuthorAlias != "Curious Hamster" {
+		t.Errorf("unexpected note %+v", note)
+	}
+	if !slices.Equal(note.Sources, []string{"https://t.co/nasa"}) || !slices.Equal(note.Tags, []string{"factual_error"}) ||
+		!slices.Equal(note.HelpfulTags, []string{"GoodSources", "Clear"}) {
+		t.Errorf("unexpected sources and tags %+v", note)
+	}
+	if other := notes.NotMisleading[0]; other.Classification != NoteNotMisleading || other.RatingStatus != NoteNotHelpful ||
+		!slices.Equal(other.Tags, []string{"personal_opinion"}) || other.TweetID != "1" {
+		t.Errorf("unexpected not misleading note %+v", other)
+	}
+}
@@ -0,0 +1,432 @@
+package helicon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// KnownAnonymousBearerToken is the bearer token web client has been shipping in main.js for years.
+// used as the last resort when it can't be found in main.js, see [Helicon.Bootstrap].
+const KnownAnonymousBearerToken = "Bearer AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
+
+var (
+	// ErrMainScriptNotFound is returned when none of the strategies can locate main.js inside the login page.
+	ErrMainScriptNotFound = errors.New("failed to locate main script source inside html")
+	// ErrBearerTokenNotFound is returned when main.js does not contain a valid bearer token.
+	// you will only see this with [BootstrapCache.DisableStaticFallback], otherwise [KnownAnonymousBearerToken] is used.
+	ErrBearerTokenNotFound = errors.New("failed to locate bearer token inside main script")
+	// ErrGuestTokenNotFound is returned when guest token is neither in the login page nor can be activated.
+	ErrGuestTokenNotFound = errors.New("failed to locate guest token")
+)
+
+// Bootstrap is what an anonymous client needs before it can talk to X.
+type Bootstrap struct {
+	// like https://abs.twimg.com/responsive-web/client-web/main.175fd69a.js
+	MainJavascriptUrl string `json:"main_javascript_url"`
+	// content hash from main.js filename (175fd69a above), changes with every frontend deploy. cache key of [BootstrapCache].
+	MainJavascriptHash string `json:"main_javascript_hash"`
+	// with `Bearer ` prefix.
+	BearerToken string `json:"bearer_token"`
+	// short-lived, never cached.
+	GuestToken string `json:"-"`
+	// when main.js was downloaded and parsed, not when this struct was created.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// BootstrapCache controls on-disk caching of [Bootstrap] results, keyed by [Bootstrap.MainJavascriptHash].
+// main.js is multiple megabytes, there is no reason to download it again until X deploys a new frontend.
+type BootstrapCache struct {
+	// default is helicon directory under [os.UserCacheDir].
+	Dir string
+	// entries older than this are refreshed even if main.js did not change. default is 24 hours.
+	TTL time.Duration
+	// do not read or write the cache at all.
+	Disabled bool
+	// do not fall back to [KnownAnonymousBearerToken] if bearer can't be found in main.js.
+	DisableStaticFallback bool
+}
+
+func (h *Helicon) bootstrapCache() BootstrapCache {
+	var cache BootstrapCache
+	if h.BootstrapCache != nil {
+		cache = *h.BootstrapCache
+	}
+	if cache.Dir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			cache.Disabled = true
+		}
+		cache.Dir = filepath.Join(dir, "helicon")
+	}
+	if cache.TTL <= 0 {
+		cache.TTL = 24 * time.Hour
+	}
+	return cache
+}
+
+func (c BootstrapCache) path(name string) string {
+	return filepath.Join(c.Dir, "bootstrap", name+".json")
+}
+
+// load reads entry named name into v, returns false if it is missing, unreadable or expired.
+func (c BootstrapCache) load(name string, v any) bool {
+	if c.Disabled {
+		return false
+	}
+	f, err := os.Open(c.path(name))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil || time.Since(stat.ModTime()) > c.TTL {
+		return false
+	}
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		slog.Warn("ignoring corrupt bootstrap cache entry", "path", c.path(name), "error", err)
+		return false
+	}
+	return true
+}
+
+// save is best effort, cache is an optimization, failing to write it should not fail the request.
+func (c BootstrapCache) save(name string, v any) {
+	if c.Disabled {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path(name)), 0o700); err != nil {
+		slog.Warn("failed to create bootstrap cache directory", "error", err)
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Warn("failed to encode bootstrap cache entry", "error", err)
+		return
+	}
+	if err := os.WriteFile(c.path(name), data, 0o600); err != nil {
+		slog.Warn("failed to write bootstrap cache entry", "error", err)
+	}
+}
+
+// Bootstrap finds main.js, the anonymous bearer token and a guest token, with a single request to the login page
+// when main.js has not changed since the last call (bearer token is cached on disk, see [BootstrapCache]).
+//
+// Every piece has multiple strategies, and errors are typed ([ErrMainScriptNotFound], [ErrBearerTokenNotFound],
+// [ErrGuestTokenNotFound]) so a page layout change does not take the whole process down with it.
+func (h *Helicon) Bootstrap() (*Bootstrap, error) {
+	page, err := h.fetchLoginPage()
+	if err != nil {
+		return nil, err
+	}
+	bootstrap, err := h.bootstrapBearer(page)
+	if err != nil {
+		return nil, err
+	}
+	if bootstrap.GuestToken, err = findGuestToken(page); err != nil {
+		if bootstrap.GuestToken, err = h.activateGuestToken(bootstrap.BearerToken); err != nil {
+			return nil, err
+		}
+	}
+	return bootstrap, nil
+}
+
+// bootstrapBearer is the bearer half of [Helicon.Bootstrap], main.js of page and the bearer token in it, without the
+// guest token.
+func (h *Helicon) bootstrapBearer(page *loginPage) (*Bootstrap, error) {
+	mainUrl, err := findMainJavascriptUrl(page.html)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap := Bootstrap{MainJavascriptUrl: mainUrl, MainJavascriptHash: mainJavascriptHash(mainUrl)}
+	cache := h.bootstrapCache()
+	var cached Bootstrap
+	if cache.load(bootstrap.MainJavascriptHash, &cached) && validBearerToken(cached.BearerToken) {
+		bootstrap.BearerToken = cached.BearerToken
+		bootstrap.FetchedAt = cached.FetchedAt
+	} else {
+		script, err := h.fetchStatic(mainUrl)
+		if err != nil {
+			return nil, err
+		}
+		bootstrap.FetchedAt = time.Now().UTC()
+		if bootstrap.BearerToken, err = findBearerToken(script); err != nil {
+			if cache.DisableStaticFallback {
+				return nil, err
+			}
+			slog.Warn("falling back to known anonymous bearer token", "main_javascript_url", mainUrl, "error", err)
+			bootstrap.BearerToken = KnownAnonymousBearerToken
+		} else {
+			cache.save(bootstrap.MainJavascriptHash, bootstrap)
+		}
+	}
+	return &bootstrap, nil
+}
+
+type loginPage struct {
+	html    string
+	cookies []*http.Cookie
+}
+
+func (h *Helicon) fetchLoginPage() (*loginPage, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest("GET", "https://x.com/i/flow/login/", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", "https://x.com/i/flow/login/", err)
+	}
+	req.Header.Set("User-Agent", h.UserAgent)
+	//goland:noinspection GoLinter
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.String(), err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.Error("failed to close request", "error", err)
+		}
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s, status: %s", resp.Request.URL.String(), resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body from %s: %w", req.URL.String(), err)
+	}
+	return &loginPage{html: string(body), cookies: resp.Cookies()}, nil
+}
+
+// fetchStatic downloads a script from abs.twimg.com.
+func (h *Helicon) fetchStatic(target string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", target, err)
+	}
+	req.Header.Set("User-Agent", h.UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.String(), err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.Error("failed to close request", "error", err)
+		}
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s, status: %s", resp.Request.URL.String(), resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body from %s: %w", req.URL.String(), err)
+	}
+	return body, nil
+}
+
+// activateGuestToken is how the web client gets a guest token when the page does not set one.
+func (h *Helicon) activateGuestToken(bearerToken string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.x.com/1.1/guest/activate.json", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to construct guest activation request: %w", err)
+	}
+	req.Header.Set("Authorization", bearerToken)
+	req.Header.Set("User-Agent", h.UserAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to hit %s: %w", req.URL.String(), err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.Error("failed to close request body", "error", err)
+		}
+	}(resp.Body)
+	var activation struct {
+		GuestToken string `json:"guest_token"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("guest activation returned status %s: %w", resp.Status, ErrGuestTokenNotFound)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&activation); err != nil {
+		return "", fmt.Errorf("failed to decode guest activation response: %w", err)
+	}
+	if !isDigits(activation.GuestToken) {
+		return "", fmt.Errorf("guest activation returned %q: %w", activation.GuestToken, ErrGuestTokenNotFound)
+	}
+	return activation.GuestToken, nil
+}
+
+var (
+	mainScriptPattern   = regexp.MustCompile(`^https://abs\.twimg\.com/responsive-web/client-web(?:-legacy)?/main\.[\w.-]+\.js$`)
+	mainScriptAnywhere  = regexp.MustCompile(`https://abs\.twimg\.com/responsive-web/client-web(?:-legacy)?/main\.[\w.-]+\.js`)
+	mainScriptHash      = regexp.MustCompile(`/main\.([0-9a-f]+)[a-z]?\.js$`)
+	bearerTokenPattern  = regexp.MustCompile(`Bearer (AAAAAAAAAAAAAAAAAAAAA[A-Za-z0-9%]{30,})`)
+	bearerStringPattern = regexp.MustCompile(`["'](AAAAAAAAAAAAAAAAAAAAA[A-Za-z0-9%]{30,})["']`)
+	guestTokenPattern   = regexp.MustCompile(`document\.cookie\s*=\s*["']gt=([0-9]+)`)
+)
+
+// findMainJavascriptUrl tries, in order, script tags, preload links (which is how the script manifest is shipped)
+// and then any main.js url anywhere in the page.
+func findMainJavascriptUrl(html string) (string, error) {
+	for _, tag := range htmlTags(html, "script") {
+		if src := tag["src"]; mainScriptPattern.MatchString(src) {
+			return src, nil
+		}
+	}
+	for _, tag := range htmlTags(html, "link") {
+		if href := tag["href"]; tag["as"] == "script" && mainScriptPattern.MatchString(href) {
+			return href, nil
+		}
+	}
+	if match := mainScriptAnywhere.FindString(html); match != "" {
+		return match, nil
+	}
+	return "", ErrMainScriptNotFound
+}
+
+// mainJavascriptHash returns the content hash in main.js filename, or hash of the url if filename does not have one.
+func mainJavascriptHash(mainUrl string) string {
+	if m := mainScriptHash.FindStringSubmatch(mainUrl); m != nil {
+		return m[1]
+	}
+	sum := sha256.Sum256([]byte(mainUrl))
+	return hex.EncodeToString(sum[:8])
+}
+
+// findBearerToken returns the last valid bearer in script, web client had multiple of them at times, and last one was the one in use.
+func findBearerToken(script []byte) (string, error) {
+	for _, re := range []*regexp.Regexp{bearerTokenPattern, bearerStringPattern} {
+		matches := re.FindAllSubmatch(script, -1)
+		for i := len(matches) - 1; i >= 0; i-- {
+			token := "Bearer " + string(matches[i][1])
+			if validBearerToken(token) {
+				return token, nil
+			}
+		}
+	}
+	return "", ErrBearerTokenNotFound
+}
+
+func validBearerToken(token string) bool {
+	raw, ok := strings.CutPrefix(token, "Bearer ")
+	if !ok || len(raw) < 50 || len(raw) > 200 {
+		return false
+	}
+	for _, r := range raw {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '%') {
+			return false
+		}
+	}
+	return true
+}
+
+func findGuestToken(page *loginPage) (string, error) {
+	if m := guestTokenPattern.FindStringSubmatch(page.html); m != nil {
+		return m[1], nil
+	}
+	for _, cookie := range page.cookies {
+		if cookie.Name == "gt" && isDigits(cookie.Value) {
+			return cookie.Value, nil
+		}
+	}
+	return "", ErrGuestTokenNotFound
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// htmlTags returns attributes of every opening tag named name. it is not a full html parser, but it handles
+// attribute order, quoting style and case, which is all we need to find script sources.
+func htmlTags(html string, name string) []map[string]string {
+	var tags []map[string]string
+	open := "<" + name
+	for i := 0; ; {
+		start := strings.IndexByte(html[i:], '<')
+		if start < 0 {
+			return tags
+		}
+		start += i
+		// names are matched on html itself, lowercasing can change byte length and shift every index after it.
+		if len(html)-start < len(open) || !strings.EqualFold(html[start:start+len(open)], open) {
+			i = start + 1
+			continue
+		}
+		start += len(open)
+		if start < len(html) && !isHTMLSpace(html[start]) && html[start] != '>' && html[start] != '/' {
+			i = start
+			continue
+		}
+		attrs, end := parseHTMLAttributes(html, start)
+		tags = append(tags, attrs)
+		i = end
+	}
+}
+
+func parseHTMLAttributes(html string, i int) (map[string]string, int) {
+	attrs := map[string]string{}
+	for i < len(html) {
+		for i < len(html) && (isHTMLSpace(html[i]) || html[i] == '/') {
+			i++
+		}
+		if i >= len(html) || html[i] == '>' {
+			return attrs, min(i+1, len(html))
+		}
+		keyStart := i
+		for i < len(html) && !isHTMLSpace(html[i]) && html[i] != '=' && html[i] != '>' {
+			i++
+		}
+		key := strings.ToLower(html[keyStart:i])
+		for i < len(html) && isHTMLSpace(html[i]) {
+			i++
+		}
+		if i >= len(html) || html[i] != '=' {
+			attrs[key] = ""
+			continue
+		}
+		i++
+		for i < len(html) && isHTMLSpace(html[i]) {
+			i++
+		}
+		if i < len(html) && (html[i] == '"' || html[i] == '\'') {
+			quote := html[i]
+			end := strings.IndexByte(html[i+1:], quote)
+			if end < 0 {
+				attrs[key] = html[i+1:]
+				return attrs, len(html)
+			}
+			attrs[key] = html[i+1 : i+1+end]
+			i += end + 2
+			continue
+		}
+		valueStart := i
+		for i < len(html) && !isHTMLSpace(html[i]) && html[i] != '>' {
+			i++
+		}
+		attrs[key] = html[valueStart:i]
+	}
+	return attrs, len(html)
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
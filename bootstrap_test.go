@@ -0,0 +1,94 @@
+package helicon
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFindMainJavascriptUrl(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		`<script type="text/javascript" charset="utf-8" nonce="x" crossorigin="anonymous" src="https://abs.twimg.com/responsive-web/client-web/main.175fd69a.js"></script>`: "https://abs.twimg.com/responsive-web/client-web/main.175fd69a.js",
+		`<SCRIPT SRC='https://abs.twimg.com/responsive-web/client-web-legacy/main.0a1b2c3a.js'></SCRIPT>`:                                                                   "https://abs.twimg.com/responsive-web/client-web-legacy/main.0a1b2c3a.js",
+		`<link rel="preload" as="script" crossorigin="anonymous" href="https://abs.twimg.com/responsive-web/client-web/main.deadbeef.js" nonce="x" />`:                      "https://abs.twimg.com/responsive-web/client-web/main.deadbeef.js",
+		`window.__SCRIPTS__=["https://abs.twimg.com/responsive-web/client-web/main.cafe1234.js"]`:                                                                           "https://abs.twimg.com/responsive-web/client-web/main.cafe1234.js",
+	}
+	for html, expected := range cases {
+		got, err := findMainJavascriptUrl(html)
+		if err != nil || got != expected {
+			t.Errorf("findMainJavascriptUrl(%q) = %q, %v, expected %q", html, got, err, expected)
+		}
+	}
+	if _, err := findMainJavascriptUrl(`<script src="/other.js"></script>`); !errors.Is(err, ErrMainScriptNotFound) {
+		t.Errorf("expected ErrMainScriptNotFound, got %v", err)
+	}
+	if hash := mainJavascriptHash("https://abs.twimg.com/responsive-web/client-web/main.175fd69a.js"); hash != "175fd69a" {
+		t.Errorf("unexpected main.js hash %q", hash)
+	}
+}
+
+func TestHTMLTags(t *testing.T) {
+	t.Parallel()
+	// pages cut inside a tag must not panic.
+	for _, html := range []string{`<p>hi</p><script `, `<script src="a.js" /`, `<script`, `<link rel=preload`} {
+		htmlTags(html, "script")
+		htmlTags(html, "link")
+	}
+	// Kelvin sign is 3 bytes, but 1 byte lowercased.
+	tags := htmlTags(strings.Repeat("\u212a", 4)+`<SCRIPT src="https://abs.twimg.com/main.js"></SCRIPT><scripts>`, "script")
+	if len(tags) != 1 || tags[0]["src"] != "https://abs.twimg.com/main.js" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+}
+
+func TestFindBearerToken(t *testing.T) {
+	t.Parallel()
+	script := []byte(`a="Bearer AAAAAAAAAAAAAAAAAAAAAFQODgEAAAAAVHTp76lzh3rFzcHbmHVvQxYYpTw%3DckAlMINMjmCwxUcaXbAN4XqJVdgMJaHqNOFgPMK0zN1qLqLQCF",b="Bearer ` + "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA" + `"`)
+	token, err := findBearerToken(script)
+	if err != nil || token != KnownAnonymousBearerToken {
+		t.Errorf("unexpected bearer %q, %v", token, err)
+	}
+	if _, err := findBearerToken([]byte(`"Bearer "+e`)); !errors.Is(err, ErrBearerTokenNotFound) {
+		t.Errorf("expected ErrBearerTokenNotFound, got %v", err)
+	}
+	page := &loginPage{html: `<script nonce="x">document.cookie="gt=1790000000000000000; Max-Age=10800; Domain=.x.com; Path=/; Secure";</script>`}
+	if gt, err := findGuestToken(page); err != nil || gt != "1790000000000000000" {
+		t.Errorf("unexpected guest token %q, %v", gt, err)
+	}
+	if _, err := findGuestToken(&loginPage{}); !errors.Is(err, ErrGuestTokenNotFound) {
+		t.Errorf("expected ErrGuestTokenNotFound, got %v", err)
+	}
+}
+
+func TestHelicon_FindAnonymousBearerToken(t *testing.T) {
+	var requested []string
+	StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		requested = append(requested, r.URL.Host+r.URL.Path)
+		body := ""
+		switch r.URL.Host + r.URL.Path {
+		case "x.com/i/flow/login/":
+			// no guest token in the page, Bootstrap would activate one.
+			body = `<script src="https://abs.twimg.com/responsive-web/client-web/main.cafe1234.js"></script>`
+		case "abs.twimg.com/responsive-web/client-web/main.cafe1234.js":
+			body = `a="` + KnownAnonymousBearerToken + `"`
+		default:
+			return StubResponse(r, http.StatusNotFound, ""), nil
+		}
+		return StubResponse(r, http.StatusOK, body), nil
+	})
+	h := &Helicon{BootstrapCache: &BootstrapCache{Disabled: true}}
+	token, err := h.FindAnonymousBearerToken()
+	if err != nil || *token != KnownAnonymousBearerToken {
+		t.Fatalf("unexpected bearer %v, %v", token, err)
+	}
+	for _, target := range requested {
+		if strings.Contains(target, "guest/activate.json") {
+			t.Errorf("bearer token must not need a guest token, requested %v", requested)
+		}
+	}
+	if _, err := h.GenerateGuestToken(); !errors.Is(err, ErrGuestTokenNotFound) {
+		t.Errorf("expected guest activation to fail with ErrGuestTokenNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+package helicon
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a GraphQL response as [ResponseCache] keeps it.
+type CachedResponse struct {
+	Operation string          `json:"operation"`
+	StoredAt  time.Time       `json:"stored_at"`
+	Meta      ResponseMeta    `json:"meta"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// CacheStore is a tier of [ResponseCache]. Stores don't expire anything by themselves, cache decides what is fresh.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	Delete(key string)
+}
+
+// ResponseCache caches responses of GraphQL queries, keyed by operation name and canonical JSON of variables, features
+// and field toggles of the request, and the logged-in user. Mutations and responses with errors are never cached.
+//
+// Lookups go through Memory first, then Disk, and a disk hit is promoted to memory. Concurrent identical requests are
+// sent once, everyone waits for the same response.
+type ResponseCache struct {
+	// TTL of operations that are not in TTL. zero means those operations are not cached.
+	DefaultTTL time.Duration
+	// per-operation TTL, zero or negative means operation is not cached.
+	TTL map[string]time.Duration
+	// how long an expired response is still returned, while it is refreshed in background. zero disables it.
+	StaleWhileRevalidate time.Duration
+	// memory tier, a [MemoryCache] of [DefaultCacheEntries] if nil.
+	Memory CacheStore
+	// optional on-disk tier, see [DiskCache].
+	Disk CacheStore
+
+	once   sync.Once
+	flight responseFlight
+}
+
+// DefaultCacheEntries is the size of the memory tier when [ResponseCache.Memory] is nil.
+const DefaultCacheEntries = 256
+
+// NewResponseCache returns a memory-only cache that keeps every query for ttl.
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{DefaultTTL: ttl}
+}
+
+func (c *ResponseCache) ttl(operation string) time.Duration {
+	if ttl, ok := c.TTL[operation]; ok {
+		return ttl
+	}
+	return c.DefaultTTL
+}
+
+func (c *ResponseCache) memory() CacheStore {
+	c.once.Do(func() {
+		if c.Memory == nil {
+			c.Memory = NewMemoryCache(DefaultCacheEntries)
+		}
+	})
+	return c.Memory
+}
+
+func (c *ResponseCache) get(key string) (*CachedResponse, bool) {
+	if cached, ok := c.memory().Get(key); ok {
+		return cached, true
+	}
+	if c.Disk == nil {
+		return nil, false
+	}
+	cached, ok := c.Disk.Get(key)
+	if ok {
+		c.memory().Set(key, cached)
+	}
+	return cached, ok
+}
+
+func (c *ResponseCache) set(key string, cached *CachedResponse) {
+	c.memory().Set(key, cached)
+	if c.Disk != nil {
+		c.Disk.Set(key, cached)
+	}
+}
+
+func (c *ResponseCache) delete(key string) {
+	c.memory().Delete(key)
+	if c.Disk != nil {
+		c.Disk.Delete(key)
+	}
+}
+
+// Invalidate drops cached response of op sent by h, next call goes to X.
+func (c *ResponseCache) Invalidate(h *Helicon, op Operation) error {
+	key, err := cacheKey(h, op)
+	if err != nil {
+		return err
+	}
+	c.delete(key)
+	return nil
+}
+
+// fetch returns the cached response of key if it is fresh, or calls fetch. ctx is what fetch is called with, it is
+// detached from cancellation when the response is refreshed in background.
+func (c *ResponseCache) fetch(ctx context.Context, operation string, key string, fetch func(context.Context) (*httpResponse, error)) (*httpResponse, error) {
+	ttl := c.ttl(operation)
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+	if cached, ok := c.get(key); ok {
+		age := time.Since(cached.StoredAt)
+		switch {
+		case age < ttl:
+			return cached.response(), nil
+		case age < ttl+c.StaleWhileRevalidate:
+			go func() {
+				if _, err := c.load(context.WithoutCancel(ctx), operation, key, fetch); err != nil {
+					slog.Warn("failed to revalidate cached response", "operation", operation, "error", err)
+				}
+			}()
+			return cached.response(), nil
+		default:
+			c.delete(key)
+		}
+	}
+	return c.load(ctx, operation, key, fetch)
+}
+
+// load calls fetch once for every concurrent caller of the same key, and caches the response if it is cacheable.
+// fetch is detached from cancellation of ctx, as others may be waiting for it, callers stop waiting when their own ctx
+// is done.
+func (c *ResponseCache) load(ctx context.Context, operation string, key string, fetch func(context.Context) (*httpResponse, error)) (*httpResponse, error) {
+	resp, err := c.flight.do(ctx, key, func() (*httpResponse, error) {
+		resp, err := fetch(context.WithoutCancel(ctx))
+		if err == nil && cacheable(resp.body) {
+			meta := resp.meta
+			// cookies of the session have no business on disk.
+			meta.Header = meta.Header.Clone()
+			meta.Header.Del("Set-Cookie")
+			c.set(key, &CachedResponse{Operation: operation, StoredAt: time.Now(), Meta: meta, Body: resp.body})
+		}
+		return resp, err
+	})
+	if resp != nil {
+		// waiters share the response, no one gets to modify it under the others.
+		resp = &httpResponse{body: bytes.Clone(resp.body), meta: resp.meta}
+	}
+	return resp, err
+}
+
+func (r *CachedResponse) response() *httpResponse {
+	meta := r.Meta
+	meta.Cached = true
+	return &httpResponse{body: bytes.Clone(r.Body), meta: meta}
+}
+
+// cacheable is true for responses with data and without errors, partial responses are not worth keeping.
+func cacheable(body []byte) bool {
+	var response struct {
+		// struct{} skips the content, only presence matters.
+		Data   *struct{}         `json:"data"`
+		Errors []json.RawMessage `json:"errors"`
+	}
+	return json.Unmarshal(body, &response) == nil && response.Data != nil && len(response.Errors) == 0
+}
+
+// cacheKey is operation name and SHA-256 of everything that changes the response. features and variables are
+// re-encoded, so key order or whitespace of the caller's types does not matter.
+func cacheKey(h *Helicon, op Operation) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(h.UserID + "\x00"))
+	for _, part := range []any{op.Variables, h.queryRegistry().OperationFeatures(op.Name).Merge(op.Features), op.FieldToggles} {
+		canonical, err := canonicalJSON(part)
+		if err != nil {
+			return "", fmt.Errorf("failed to build cache key of %s: %w", op.Name, err)
+		}
+		hash.Write(canonical)
+		hash.Write([]byte{0})
+	}
+	return op.Name + "-" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// canonicalJSON marshals v with object keys sorted at every level.
+func canonicalJSON(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	// maps are marshalled with sorted keys.
+	return json.Marshal(decoded)
+}
+
+type responseFlight struct {
+	mu    sync.Mutex
+	calls map[string]*responseCall
+}
+
+type responseCall struct {
+	done chan struct{}
+	resp *httpResponse
+	err  error
+}
+
+// do runs fn once for every concurrent caller of key. fn runs on its own, first caller giving up does not take it down
+// for the others, everyone waits until fn returns or their ctx is done.
+func (f *responseFlight) do(ctx context.Context, key string, fn func() (*httpResponse, error)) (*httpResponse, error) {
+	f.mu.Lock()
+	call, ok := f.calls[key]
+	if !ok {
+		if f.calls == nil {
+			f.calls = map[string]*responseCall{}
+		}
+		call = &responseCall{done: make(chan struct{})}
+		f.calls[key] = call
+		go func() {
+			call.resp, call.err = fn()
+			f.mu.Lock()
+			delete(f.calls, key)
+			f.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.resp, call.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("stopped waiting for response: %w", context.Cause(ctx))
+	}
+}
+
+// MemoryCache is an LRU [CacheStore].
+type MemoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryCache returns a cache that keeps maxEntries most recently used responses.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{maxEntries: maxEntries, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryEntry).response, true
+}
+
+func (m *MemoryCache) Set(key string, response *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryEntry).response = response
+		m.order.MoveToFront(element)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, response: response})
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.order.Remove(element)
+		delete(m.entries, key)
+	}
+}
+
+// DiskCache is a [CacheStore] of JSON files, one per response. Like bootstrap cache, it is best effort, failing to
+// read or write it never fails a request.
+type DiskCache struct {
+	// default is helicon/responses directory under [os.UserCacheDir].
+	Dir string
+}
+
+func (d DiskCache) path(key string) string {
+	dir := d.Dir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(cacheDir, "helicon", "responses")
+	}
+	return filepath.Join(dir, key+".json")
+}
+
+func (d DiskCache) Get(key string) (*CachedResponse, bool) {
+	path := d.path(key)
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cached CachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		slog.Warn("ignoring corrupt response cache entry", "path", path, "error", err)
+		return nil, false
+	}
+	return &cached, true
+}
+
+func (d DiskCache) Set(key string, response *CachedResponse) {
+	path := d.path(key)
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		slog.Warn("failed to create response cache directory", "error", err)
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		slog.Warn("failed to encode response cache entry", "error", err)
+		return
+	}
+	// written next to the entry and renamed over it, so concurrent readers never see half of it. every writer gets its
+	// own temp file, processes sharing the directory would tear each other's entries otherwise.
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+"*.tmp")
+	if err != nil {
+		slog.Warn("failed to write response cache entry", "error", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		slog.Warn("failed to write response cache entry", "error", err)
+	}
+}
+
+func (d DiskCache) Delete(key string) {
+	if path := d.path(key); path != "" {
+		_ = os.Remove(path)
+	}
+}
+
+// cachedRoundTrip is how [Helicon.Execute] sends op, through [Helicon.Cache] if op can be cached.
+func (h *Helicon) cachedRoundTrip(ctx context.Context, op Operation, send func(context.Context) (*httpResponse, error)) (*httpResponse, error) {
+	if h.Cache == nil || h.operationType(op) == OperationMutation {
+		return send(ctx)
+	}
+	key, err := cacheKey(h, op)
+	if err != nil {
+		return nil, err
+	}
+	return h.Cache.fetch(ctx, op.Name, key, send)
+}
@@ -0,0 +1,281 @@
+package helicon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+	c := &ResponseCache{DefaultTTL: time.Minute, TTL: map[string]time.Duration{"Uncached": 0}, StaleWhileRevalidate: time.Minute}
+	var calls atomic.Int32
+	fetch := func(context.Context) (*httpResponse, error) {
+		calls.Add(1)
+		return &httpResponse{body: []byte(`{"data":{"n":1}}`), meta: ResponseMeta{StatusCode: 200, Header: http.Header{"Set-Cookie": {"ct0=secret"}}}}, nil
+	}
+	ctx := context.Background()
+
+	resp, err := c.fetch(ctx, "TweetDetail", "key", fetch)
+	if err != nil || resp.meta.Cached {
+		t.Fatalf("first call must be a request: %+v %v", resp, err)
+	}
+	resp, err = c.fetch(ctx, "TweetDetail", "key", fetch)
+	if err != nil || !resp.meta.Cached || string(resp.body) != `{"data":{"n":1}}` || calls.Load() != 1 {
+		t.Fatalf("second call must be a hit: %+v %v, %d calls", resp, err, calls.Load())
+	}
+	if resp.meta.Header.Get("Set-Cookie") != "" {
+		t.Fatal("cookies must not be cached")
+	}
+	resp.body[0] = 'x'
+	if hit, _ := c.get("key"); hit.Body[0] != '{' {
+		t.Fatal("hits must not share the cached body")
+	}
+
+	if _, _ = c.fetch(ctx, "Uncached", "other", fetch); calls.Load() != 2 {
+		t.Fatal("operations with zero TTL must not be cached")
+	}
+	if _, ok := c.get("other"); ok {
+		t.Fatal("operations with zero TTL must not be stored")
+	}
+
+	// expired, but stale responses are still served while refreshed in background.
+	stale, _ := c.get("key")
+	stale.StoredAt = time.Now().Add(-90 * time.Second)
+	cached := stale
+	resp, _ = c.fetch(ctx, "TweetDetail", "key", fetch)
+	if !resp.meta.Cached {
+		t.Fatal("stale response must be served")
+	}
+	deadline := time.Now().Add(time.Second)
+	for cached == stale && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		cached, _ = c.get("key")
+	}
+	if cached == stale || calls.Load() != 3 {
+		t.Fatal("stale response must be revalidated")
+	}
+
+	cached.StoredAt = time.Now().Add(-3 * time.Minute)
+	if resp, _ = c.fetch(ctx, "TweetDetail", "key", fetch); resp.meta.Cached || calls.Load() != 4 {
+		t.Fatal("responses beyond stale window must be fetched again")
+	}
+}
+
+func TestResponseCache_Uncacheable(t *testing.T) {
+	t.Parallel()
+	c := NewResponseCache(time.Minute)
+	for _, body := range []string{`{"errors":[{"message":"Rate limit exceeded"}]}`, `{"data":{"n":1},"errors":[{"message":"partial"}]}`, `{"data":null}`} {
+		_, _ = c.fetch(context.Background(), "TweetDetail", body, func(context.Context) (*httpResponse, error) {
+			return &httpResponse{body: []byte(body)}, nil
+		})
+		if _, ok := c.get(body); ok {
+			t.Errorf("%s must not be cached", body)
+		}
+	}
+}
+
+func TestResponseCache_Singleflight(t *testing.T) {
+	t.Parallel()
+	c := NewResponseCache(time.Minute)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (*httpResponse, error) {
+		calls.Add(1)
+		<-release
+		return &httpResponse{body: []byte(`{"data":{}}`)}, nil
+	}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.load(context.Background(), "TweetDetail", "key", fetch); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// let every caller get into the flight before the request finishes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("concurrent identical requests must be sent once, sent %d times", calls.Load())
+	}
+}
+
+func TestResponseCache_Cancel(t *testing.T) {
+	t.Parallel()
+	c := NewResponseCache(time.Minute)
+	release := make(chan struct{})
+	fetched := make(chan error, 1)
+	fetch := func(ctx context.Context) (*httpResponse, error) {
+		<-release
+		fetched <- ctx.Err()
+		return &httpResponse{body: []byte(`{"data":{}}`)}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.load(ctx, "TweetDetail", "key", fetch)
+		first <- err
+	}()
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.load(context.Background(), "TweetDetail", "key", fetch)
+		second <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller must stop waiting, got %v", err)
+	}
+	close(release)
+	if err := <-fetched; err != nil {
+		t.Fatalf("request must not be canceled with the caller that started it, got %v", err)
+	}
+	if err := <-second; err != nil {
+		t.Fatalf("other callers must get the response, got %v", err)
+	}
+	if _, ok := c.get("key"); !ok {
+		t.Fatal("response must be cached")
+	}
+}
+
+func TestHelicon_Execute_Cache(t *testing.T) {
+	var requests atomic.Int32
+	StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		body := `{"data":{"user":{"result":{"rest_id":"12"}}}}`
+		return StubResponse(r, http.StatusOK, body), nil
+	})
+	h := stubbedHelicon()
+	h.Cache = NewResponseCache(time.Minute)
+	op := Operation{Name: "UserByScreenName", Variables: map[string]any{"screen_name": "jack"}}
+	execute := func(opts ...ExecuteOption) Envelope {
+		t.Helper()
+		var env Envelope
+		var out struct {
+			User struct {
+				Result struct {
+					RestId string `json:"rest_id"`
+				} `json:"result"`
+			} `json:"user"`
+		}
+		if err := h.Execute(context.Background(), op, &out, append(opts, WithEnvelope(&env))...); err != nil {
+			t.Fatal(err)
+		}
+		if out.User.Result.RestId != "12" {
+			t.Fatalf("unexpected data %s", env.Data)
+		}
+		return env
+	}
+
+	if env := execute(); env.HTTP.Cached || requests.Load() != 1 {
+		t.Fatalf("first call must be a request, %d requests", requests.Load())
+	}
+	if env := execute(); !env.HTTP.Cached || requests.Load() != 1 {
+		t.Fatalf("second call must be served from cache, %d requests", requests.Load())
+	}
+	if env := execute(WithoutCache()); env.HTTP.Cached || requests.Load() != 2 {
+		t.Fatalf("WithoutCache must send a request, %d requests", requests.Load())
+	}
+	h.Cache = nil
+	if env := execute(); env.HTTP.Cached || requests.Load() != 3 {
+		t.Fatalf("nothing must be cached without a cache, %d requests", requests.Load())
+	}
+}
+
+func TestMemoryCache_LRU(t *testing.T) {
+	t.Parallel()
+	m := NewMemoryCache(2)
+	m.Set("a", &CachedResponse{})
+	m.Set("b", &CachedResponse{})
+	m.Get("a")
+	m.Set("c", &CachedResponse{})
+	if _, ok := m.Get("b"); ok {
+		t.Fatal("least recently used entry must be evicted")
+	}
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("recently used entry must be kept")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	t.Parallel()
+	c := &ResponseCache{DefaultTTL: time.Minute, Disk: DiskCache{Dir: t.TempDir()}}
+	c.set("key", &CachedResponse{Operation: "TweetDetail", StoredAt: time.Now(), Body: []byte(`{"data":{}}`)})
+	// a fresh process has an empty memory tier.
+	restarted := &ResponseCache{DefaultTTL: time.Minute, Disk: c.Disk}
+	cached, ok := restarted.get("key")
+	if !ok || cached.Operation != "TweetDetail" || string(cached.Body) != `{"data":{}}` {
+		t.Fatalf("entry must be read from disk: %+v", cached)
+	}
+	if _, ok := restarted.memory().Get("key"); !ok {
+		t.Fatal("disk hits must be promoted to memory")
+	}
+	restarted.delete("key")
+	if _, ok := c.Disk.Get("key"); ok {
+		t.Fatal("entry must be deleted from disk")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+	h := &Helicon{QueryRegistry: &QueryRegistry{}, UserID: "1"}
+	key := func(h *Helicon, variables any) string {
+		k, err := cacheKey(h, Operation{Name: "TweetDetail", Variables: variables})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	type variables struct {
+		B int    `json:"b"`
+		A string `json:"a"`
+	}
+	if key(h, variables{B: 1, A: "x"}) != key(h, map[string]any{"a": "x", "b": 1}) {
+		t.Error("same variables must have the same key, whatever type they are in")
+	}
+	if key(h, variables{B: 1}) == key(h, variables{B: 2}) {
+		t.Error("different variables must have different keys")
+	}
+	if key(h, variables{}) == key(&Helicon{QueryRegistry: h.QueryRegistry, UserID: "2"}, variables{}) {
+		t.Error("different accounts must have different keys")
+	}
+}
+
+func TestDiskCache_ConcurrentSet(t *testing.T) {
+	t.Parallel()
+	disk := DiskCache{Dir: t.TempDir()}
+	bodies := map[string]bool{}
+	var wg sync.WaitGroup
+	for i := range 16 {
+		body := fmt.Sprintf(`{"data":{"writer":%d,"padding":"%s"}}`, i, strings.Repeat("x", 64<<10))
+		bodies[body] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			disk.Set("key", &CachedResponse{Operation: "TweetDetail", Body: []byte(body)})
+		}()
+	}
+	wg.Wait()
+	cached, ok := disk.Get("key")
+	if !ok || !bodies[string(cached.Body)] {
+		t.Fatal("concurrent writers must leave one whole entry")
+	}
+	entries, err := os.ReadDir(disk.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temp files must not be left behind, got %d files", len(entries))
+	}
+}
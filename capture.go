@@ -0,0 +1,122 @@
+package helicon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Capture is a recorded GraphQL request of the web client and what X responded with, one line of the JSONL files
+// helicon-gen and helicon-drift read.
+//
+//	{"method":"GET","url":"https://x.com/i/api/graphql/<queryId>/TweetDetail?variables=...&features=...","response":{"data":{...}}}
+//
+// Body is only needed for POST requests. Body and Response can also be JSON encoded strings, which is what most
+// proxies and HAR exports give you.
+type Capture struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Operation returns query ID and name of the operation, parsed from `/i/api/graphql/<queryId>/<operationName>`.
+func (c Capture) Operation() (queryID string, name string, err error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid url: %w", err)
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	i := len(segments) - 3
+	if i < 0 || segments[i] != "graphql" {
+		return "", "", fmt.Errorf("%s is not a GraphQL request", c.URL)
+	}
+	return segments[i+1], segments[i+2], nil
+}
+
+// Mutation is true if request was a POST.
+func (c Capture) Mutation() bool {
+	return strings.EqualFold(c.Method, http.MethodPost)
+}
+
+// Params returns variables, features and fieldToggles request was sent with, whichever there is. GET sends them in
+// the query string, POST in the body.
+func (c Capture) Params() (map[string]json.RawMessage, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	params := map[string]json.RawMessage{}
+	for _, key := range []string{"variables", "features", "fieldToggles"} {
+		if value := u.Query().Get(key); value != "" {
+			params[key] = json.RawMessage(value)
+		}
+	}
+	if c.Mutation() && len(c.Body) > 0 {
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(unquoteJSON(c.Body), &body); err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		for _, key := range []string{"variables", "features", "fieldToggles"} {
+			if value, ok := body[key]; ok {
+				params[key] = unquoteJSON(value)
+			}
+		}
+	}
+	return params, nil
+}
+
+// Data returns `data` of the response, error if there is none.
+func (c Capture) Data() (json.RawMessage, error) {
+	var response graphQLResponse
+	if err := json.Unmarshal(unquoteJSON(c.Response), &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if len(response.Data) == 0 || bytes.Equal(response.Data, []byte("null")) {
+		if len(response.Errors) > 0 {
+			return nil, GraphQLErrors(response.Errors)
+		}
+		return nil, fmt.Errorf("response has no data")
+	}
+	return response.Data, nil
+}
+
+// ReadCaptures calls fn with every capture of JSONL r, and line number it is at. Reading stops at the first error.
+func ReadCaptures(r io.Reader, fn func(line int, c Capture) error) error {
+	scanner := bufio.NewScanner(r)
+	// TweetDetail responses of long threads are easily a few megabytes.
+	scanner.Buffer(make([]byte, 0, 1<<20), 64<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var c Capture
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(line, c); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read captures: %w", err)
+	}
+	return nil
+}
+
+// unquoteJSON returns the JSON inside raw if raw is a JSON string, raw otherwise.
+func unquoteJSON(raw json.RawMessage) json.RawMessage {
+	var s string
+	if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return json.RawMessage(s)
+	}
+	return raw
+}
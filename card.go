@@ -0,0 +1,300 @@
+package helicon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RawCard is `card` of a tweet, link previews, polls and ads. everything in it is a binding value, a list of keys and
+// values, see [Card] for them decoded.
+type RawCard struct {
+	RestId string `json:"rest_id"`
+	Legacy struct {
+		BindingValues []CardBindingValue `json:"binding_values"`
+		// "summary", "summary_large_image", "poll2choice_text_only", "unified_card"...
+		Name string `json:"name"`
+		Url  string `json:"url"`
+		// users in binding values, site of the link preview for example.
+		UserRefsResults []UserResults `json:"user_refs_results,omitempty"`
+	} `json:"legacy"`
+}
+
+type CardBindingValue struct {
+	Key   string    `json:"key"`
+	Value CardValue `json:"value"`
+}
+
+// CardValue is a binding value, one of the values is set, which one is Type.
+type CardValue struct {
+	// "STRING", "BOOLEAN", "IMAGE", "USER", "IMAGE_COLOR".
+	Type         string     `json:"type"`
+	StringValue  string     `json:"string_value,omitempty"`
+	BooleanValue bool       `json:"boolean_value,omitempty"`
+	ImageValue   *CardImage `json:"image_value,omitempty"`
+	UserValue    *struct {
+		IdStr string   `json:"id_str"`
+		Path  []string `json:"path,omitempty"`
+	} `json:"user_value,omitempty"`
+	ImageColorValue json.RawMessage `json:"image_color_value,omitempty"`
+	ScribeKey       string          `json:"scribe_key,omitempty"`
+}
+
+type CardImage struct {
+	Url    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Alt    string `json:"alt,omitempty"`
+}
+
+// CardType is what kind of [Card] it is, which of its fields are set.
+type CardType string
+
+const (
+	// CardSummary is a link preview with a thumbnail, Link is set.
+	CardSummary CardType = "summary"
+	// CardSummaryLargeImage is a link preview with a large image, Link is set.
+	CardSummaryLargeImage CardType = "summary_large_image"
+	// CardPoll is a poll of any number of choices, with or without images, Poll is set.
+	CardPoll CardType = "poll"
+	// CardUnified is an ad or app card made of components, Unified is set.
+	CardUnified CardType = "unified_card"
+)
+
+// Card is a card of a tweet, decoded from binding values. for types other than [CardType] constants, only Values are
+// there. same goes for cards that fail to decode, a poll with a count that is not a number for example, Type is empty
+// for them.
+type Card struct {
+	// name of the card, Type is the same unless it is a poll or none of [CardType] constants.
+	Name    string       `json:"name"`
+	Type    CardType     `json:"type"`
+	URL     string       `json:"url,omitempty"`
+	Link    *LinkPreview `json:"link,omitempty"`
+	Poll    *Poll        `json:"poll,omitempty"`
+	Unified *UnifiedCard `json:"unified,omitempty"`
+	// every binding value by key, whatever the type is.
+	Values map[string]CardValue `json:"values,omitempty"`
+}
+
+// LinkPreview is the preview of a link in a tweet.
+type LinkPreview struct {
+	// t.co link, expanded one is in entities of the tweet.
+	URL         string     `json:"url"`
+	Domain      string     `json:"domain,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Image       *CardImage `json:"image,omitempty"`
+	// ID of the account of the site, if it has one.
+	SiteUserID string `json:"site_user_id,omitempty"`
+}
+
+type Poll struct {
+	Choices []PollChoice `json:"choices"`
+	EndsAt  time.Time    `json:"ends_at"`
+	// counts are as of this, they are cached by X while the poll is open.
+	UpdatedAt time.Time     `json:"updated_at,omitzero"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	// counts won't change anymore, poll is over.
+	Final bool `json:"final"`
+	// choice the logged-in account voted for, starting from 1, zero if it didn't.
+	Voted int `json:"voted,omitempty"`
+}
+
+type PollChoice struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// Total is the number of votes.
+func (p *Poll) Total() int {
+	total := 0
+	for _, choice := range p.Choices {
+		total += choice.Count
+	}
+	return total
+}
+
+// UnifiedCard is a card made of components, media, details, buttons, that lead to a website or an app.
+type UnifiedCard struct {
+	// "image_website", "video_website", "image_app", "image_carousel_website"...
+	Type     string   `json:"type"`
+	Title    string   `json:"title,omitempty"`
+	Subtitle string   `json:"subtitle,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Media    []*Media `json:"media,omitempty"`
+}
+
+// unifiedCardJSON is `unified_card` binding value, JSON in a string.
+type unifiedCardJSON struct {
+	Type             string   `json:"type"`
+	Components       []string `json:"components"`
+	ComponentObjects map[string]struct {
+		Type string `json:"type"`
+		Data struct {
+			Title struct {
+				Content string `json:"content"`
+			} `json:"title"`
+			Subtitle struct {
+				Content string `json:"content"`
+			} `json:"subtitle"`
+			// media.
+			Id          string `json:"id"`
+			Destination string `json:"destination"`
+			// swipeable_media.
+			MediaList []struct {
+				Id          string `json:"id"`
+				Destination string `json:"destination"`
+			} `json:"media_list"`
+		} `json:"data"`
+	} `json:"component_objects"`
+	DestinationObjects map[string]struct {
+		Type string `json:"type"`
+		Data struct {
+			UrlData struct {
+				Url    string `json:"url"`
+				Vanity string `json:"vanity"`
+			} `json:"url_data"`
+		} `json:"data"`
+	} `json:"destination_objects"`
+	MediaEntities map[string]RawMedia `json:"media_entities"`
+}
+
+// linkImageKeys are binding values of link preview images, largest first.
+var linkImageKeys = []string{
+	"photo_image_full_size_original", "summary_photo_image_original", "thumbnail_image_original",
+	"photo_image_full_size_large", "summary_photo_image_large", "thumbnail_image_large", "thumbnail_image",
+}
+
+// card decodes binding values. a card that does not decode is not worth losing the tweet over, it is logged and left
+// with its values only.
+func (raw *RawCard) card() *Card {
+	card := &Card{
+		Name:   raw.Legacy.Name,
+		Type:   CardType(raw.Legacy.Name),
+		URL:    raw.Legacy.Url,
+		Values: make(map[string]CardValue, len(raw.Legacy.BindingValues)),
+	}
+	for _, binding := range raw.Legacy.BindingValues {
+		card.Values[binding.Key] = binding.Value
+	}
+	var err error
+	switch {
+	case card.Type == CardSummary || card.Type == CardSummaryLargeImage:
+		card.Link = card.link()
+	case strings.HasPrefix(card.Name, "poll") && strings.Contains(card.Name, "choice"):
+		card.Type = CardPoll
+		card.Poll, err = card.poll()
+	case card.Type == CardUnified:
+		card.Unified, err = card.unified()
+	}
+	if err != nil {
+		slog.Warn("failed to decode card", "card", card.Name, "url", card.URL, "error", err)
+		card.Type, card.Link, card.Poll, card.Unified = "", nil, nil, nil
+	}
+	return card
+}
+
+func (c *Card) link() *LinkPreview {
+	link := &LinkPreview{
+		URL:         c.Values["card_url"].StringValue,
+		Domain:      c.Values["domain"].StringValue,
+		Title:       c.Values["title"].StringValue,
+		Description: c.Values["description"].StringValue,
+	}
+	if link.Domain == "" {
+		link.Domain = c.Values["vanity_url"].StringValue
+	}
+	for _, key := range linkImageKeys {
+		if image := c.Values[key].ImageValue; image != nil {
+			link.Image = image
+			break
+		}
+	}
+	if site := c.Values["site"].UserValue; site != nil {
+		link.SiteUserID = site.IdStr
+	}
+	return link
+}
+
+func (c *Card) poll() (*Poll, error) {
+	poll := &Poll{Final: c.Values["counts_are_final"].BooleanValue}
+	for i := 1; ; i++ {
+		label, ok := c.Values["choice"+strconv.Itoa(i)+"_label"]
+		if !ok {
+			break
+		}
+		count, err := parseCount(c.Values["choice"+strconv.Itoa(i)+"_count"].StringValue)
+		if err != nil {
+			return nil, fmt.Errorf("choice %d: %w", i, err)
+		}
+		poll.Choices = append(poll.Choices, PollChoice{Label: label.StringValue, Count: count})
+	}
+	var err error
+	if poll.EndsAt, err = parseCardTime(c.Values["end_datetime_utc"].StringValue); err != nil {
+		return nil, fmt.Errorf("end time: %w", err)
+	}
+	if poll.UpdatedAt, err = parseCardTime(c.Values["last_updated_datetime_utc"].StringValue); err != nil {
+		return nil, fmt.Errorf("last updated time: %w", err)
+	}
+	minutes, err := parseCount(c.Values["duration_minutes"].StringValue)
+	if err != nil {
+		return nil, fmt.Errorf("duration: %w", err)
+	}
+	poll.Duration = time.Duration(minutes) * time.Minute
+	if poll.Voted, err = parseCount(c.Values["selected_choice"].StringValue); err != nil {
+		return nil, fmt.Errorf("selected choice: %w", err)
+	}
+	return poll, nil
+}
+
+func (c *Card) unified() (*UnifiedCard, error) {
+	var raw unifiedCardJSON
+	if err := json.Unmarshal([]byte(c.Values["unified_card"].StringValue), &raw); err != nil {
+		return nil, fmt.Errorf("unified card: %w", err)
+	}
+	unified := &UnifiedCard{Type: raw.Type}
+	destination := func(key string) {
+		if unified.URL == "" {
+			unified.URL = raw.DestinationObjects[key].Data.UrlData.Url
+		}
+	}
+	addMedia := func(id string) {
+		if media, ok := raw.MediaEntities[id]; ok {
+			unified.Media = append(unified.Media, media.media())
+		}
+	}
+	for _, key := range raw.Components {
+		component := raw.ComponentObjects[key]
+		switch component.Type {
+		case "details":
+			if unified.Title == "" {
+				unified.Title, unified.Subtitle = component.Data.Title.Content, component.Data.Subtitle.Content
+			}
+			destination(component.Data.Destination)
+		case "media":
+			addMedia(component.Data.Id)
+			destination(component.Data.Destination)
+		case "swipeable_media":
+			for _, item := range component.Data.MediaList {
+				addMedia(item.Id)
+				destination(item.Destination)
+			}
+		}
+	}
+	return unified, nil
+}
+
+// parseCardTime parses times of binding values, 2024-06-01T12:00:00Z. empty is zero time.
+func parseCardTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse card time %q: %w", value, err)
+	}
+	return t, nil
+}
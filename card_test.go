@@ -0,0 +1,113 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"testing"
+	"time"
+)
+
+func TestExtractTweets_Card(t *testing.T) {
+	t.Parallel()
+	set, err := helicon.ExtractTweets([]byte(`{"entries":[
+		{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1","legacy":{"id_str":"1","full_text":"https://t.co/go"},
+			"card":{"rest_id":"https://t.co/go","legacy":{"name":"summary_large_image","url":"https://t.co/go",
+				"binding_values":[
+					{"key":"title","value":{"type":"STRING","string_value":"The Go Programming Language"}},
+					{"key":"description","value":{"type":"STRING","string_value":"Go is an open source programming language."}},
+					{"key":"vanity_url","value":{"type":"STRING","string_value":"go.dev","scribe_key":"vanity_url"}},
+					{"key":"card_url","value":{"type":"STRING","string_value":"https://t.co/go","scribe_key":"card_url"}},
+					{"key":"thumbnail_image_large","value":{"type":"IMAGE","image_value":{"url":"https://pbs.twimg.com/card_img/1/small","width":420,"height":220}}},
+					{"key":"summary_photo_image_original","value":{"type":"IMAGE","image_value":{"url":"https://pbs.twimg.com/card_img/1/orig","width":1200,"height":630}}},
+					{"key":"site","value":{"type":"USER","user_value":{"id_str":"300","path":[]},"scribe_key":"publisher_id"}}],
+				"user_refs_results":[{"result":{"__typename":"User","rest_id":"300","legacy":{"screen_name":"golang"}}}]}}}}},
+		{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"2","legacy":{"id_str":"2","full_text":"tabs or spaces?"},
+			"card":{"rest_id":"card://2","legacy":{"name":"poll3choice_text_only","url":"card://2",
+				"binding_values":[
+					{"key":"choice1_label","value":{"type":"STRING","string_value":"tabs"}},
+					{"key":"choice1_count","value":{"type":"STRING","string_value":"120"}},
+					{"key":"choice2_label","value":{"type":"STRING","string_value":"spaces"}},
+					{"key":"choice2_count","value":{"type":"STRING","string_value":"80"}},
+					{"key":"choice3_label","value":{"type":"STRING","string_value":"gofmt"}},
+					{"key":"choice3_count","value":{"type":"STRING","string_value":"300"}},
+					{"key":"selected_choice","value":{"type":"STRING","string_value":"3"}},
+					{"key":"end_datetime_utc","value":{"type":"STRING","string_value":"2024-06-02T12:00:00Z"}},
+					{"key":"last_updated_datetime_utc","value":{"type":"STRING","string_value":"2024-06-02T12:00:01Z"}},
+					{"key":"duration_minutes","value":{"type":"STRING","string_value":"1440"}},
+					{"key":"counts_are_final","value":{"type":"BOOLEAN","boolean_value":true}}]}}}}},
+		{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"3","legacy":{"id_str":"3","full_text":"download it"},
+			"card":{"rest_id":"card://3","legacy":{"name":"unified_card","url":"card://3",
+				"binding_values":[{"key":"unified_card","value":{"type":"STRING","string_value":` +
+		`"{\"type\":\"image_website\",\"components\":[\"media_1\",\"details_1\"],` +
+		`\"component_objects\":{\"media_1\":{\"type\":\"media\",\"data\":{\"id\":\"13\",\"destination\":\"browser_1\"}},` +
+		`\"details_1\":{\"type\":\"details\",\"data\":{\"title\":{\"content\":\"Helicon\"},\"subtitle\":{\"content\":\"github.com\"},\"destination\":\"browser_1\"}}},` +
+		`\"destination_objects\":{\"browser_1\":{\"type\":\"browser\",\"data\":{\"url_data\":{\"url\":\"https://github.com/caner-cetin/helicon\",\"vanity\":\"github.com\"}}}},` +
+		`\"media_entities\":{\"13\":{\"id\":13,\"id_str\":\"13\",\"type\":\"photo\",\"media_url_https\":\"https://pbs.twimg.com/media/GCard.jpg\"}}}"}}]}}}}},
+		{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"4","legacy":{"id_str":"4","full_text":"live"},
+			"card":{"rest_id":"card://4","legacy":{"name":"745291183405076480:broadcast","url":"card://4",
+				"binding_values":[{"key":"broadcast_id","value":{"type":"STRING","string_value":"1YqKDqLbWnmGV"}}]}}}}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link := set.Tweet("1").Card
+	if link.Type != helicon.CardSummaryLargeImage || link.Link == nil {
+		t.Fatalf("expected a link preview, got %+v", link)
+	}
+	if link.Link.Title != "The Go Programming Language" || link.Link.Domain != "go.dev" || link.Link.URL != "https://t.co/go" ||
+		link.Link.Image.Url != "https://pbs.twimg.com/card_img/1/orig" || link.Link.SiteUserID != "300" {
+		t.Errorf("unexpected link preview %+v", link.Link)
+	}
+	if site := set.User("300"); site == nil || site.ScreenName != "golang" {
+		t.Error("users of cards must be in the set")
+	}
+
+	poll := set.Tweet("2").Card
+	if poll.Type != helicon.CardPoll || poll.Name != "poll3choice_text_only" || poll.Poll == nil {
+		t.Fatalf("expected a poll, got %+v", poll)
+	}
+	want := []helicon.PollChoice{{Label: "tabs", Count: 120}, {Label: "spaces", Count: 80}, {Label: "gofmt", Count: 300}}
+	if len(poll.Poll.Choices) != 3 || poll.Poll.Choices[0] != want[0] || poll.Poll.Choices[1] != want[1] || poll.Poll.Choices[2] != want[2] {
+		t.Errorf("unexpected choices %+v", poll.Poll.Choices)
+	}
+	if poll.Poll.Total() != 500 || !poll.Poll.Final || poll.Poll.Voted != 3 || poll.Poll.Duration != 24*time.Hour ||
+		!poll.Poll.EndsAt.Equal(time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected poll %+v", poll.Poll)
+	}
+
+	unified := set.Tweet("3").Card.Unified
+	if unified == nil || unified.Type != "image_website" || unified.Title != "Helicon" || unified.URL != "https://github.com/caner-cetin/helicon" ||
+		len(unified.Media) != 1 || unified.Media[0].URL != "https://pbs.twimg.com/media/GCard.jpg" {
+		t.Errorf("unexpected unified card %+v", unified)
+	}
+
+	other := set.Tweet("4").Card
+	if other.Type != "745291183405076480:broadcast" || other.Link != nil || other.Poll != nil || other.Unified != nil ||
+		other.Values["broadcast_id"].StringValue != "1YqKDqLbWnmGV" {
+		t.Errorf("unknown cards must only have binding values, got %+v", other)
+	}
+}
+
+func TestExtractTweets_MalformedCard(t *testing.T) {
+	t.Parallel()
+	set, err := helicon.ExtractTweets([]byte(`{"entries":[
+		{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1","legacy":{"id_str":"1","full_text":"ad"},
+			"card":{"rest_id":"card://1","legacy":{"name":"unified_card","url":"card://1",
+				"binding_values":[{"key":"card_url","value":{"type":"STRING","string_value":"https://t.co/ad"}}]}}}}},
+		{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"2","legacy":{"id_str":"2","full_text":"vote"},
+			"card":{"rest_id":"card://2","legacy":{"name":"poll2choice_text_only","url":"card://2",
+				"binding_values":[
+					{"key":"choice1_label","value":{"type":"STRING","string_value":"yes"}},
+					{"key":"choice1_count","value":{"type":"STRING","string_value":"1,234"}}]}}}}}]}`))
+	if err != nil {
+		t.Fatalf("a card that does not decode must not fail the tweets, got %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		tweet := set.Tweet(id)
+		if tweet == nil || tweet.Card == nil {
+			t.Fatalf("expected tweet %s with its card, got %+v", id, tweet)
+		}
+		if card := tweet.Card; card.Type != "" || card.Link != nil || card.Poll != nil || card.Unified != nil || card.Name == "" || len(card.Values) == 0 {
+			t.Errorf("expected only name and values of card of tweet %s, got %+v", id, card)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Command helicon-drift checks recorded traffic against the types responses are decoded into, and reports fields X
+// added, removed or changed the type of. Run it nightly against fresh captures to catch breakage before users do.
+//
+// Input is the same JSONL helicon-gen reads, see [helicon.Capture]. Operations are checked against
+// [helicon.ResponseTypes], captures of other operations are counted and skipped.
+//
+// Usage:
+//
+//	helicon-drift [-json] captures.jsonl...
+//
+// Reads stdin if no file is given. Exits with 1 if anything drifted, 2 if captures could not be read.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/caner-cetin/helicon"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "write report as JSON")
+	flag.Parse()
+	report, err := check(flag.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "helicon-drift:", err)
+		os.Exit(2)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "helicon-drift:", err)
+		os.Exit(2)
+	}
+	if report.HasDrift() {
+		os.Exit(1)
+	}
+}
+
+func check(inputs []string) (*helicon.DriftReport, error) {
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	report := &helicon.DriftReport{}
+	unknown := map[string]int{}
+	for _, input := range inputs {
+		err := readInput(input, func(line int, c helicon.Capture) error {
+			checked, err := report.CheckCapture(c)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "%s:%d: skipping, %s\n", input, line, err)
+				return nil
+			}
+			if !checked {
+				_, operation, _ := c.Operation()
+				unknown[operation]++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(unknown) > 0 {
+		var skipped []string
+		for _, operation := range slices.Sorted(maps.Keys(unknown)) {
+			skipped = append(skipped, fmt.Sprintf("%s (%d)", operation, unknown[operation]))
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "no response type for %s\n", strings.Join(skipped, ", "))
+	}
+	return report, nil
+}
+
+func readInput(input string, fn func(line int, c helicon.Capture) error) error {
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("failed to open captures: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	if err := helicon.ReadCaptures(r, fn); err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/caner-cetin/helicon"
+	"io"
+)
+
+// operation is everything learned about one operation from every capture of it.
+type operation struct {
+	name     string
+	queryID  string
+	mutation bool
+	samples  int
+
+	variables    *node
+	features     *node
+	fieldToggles *node
+	data         *node
+	unified      bool
+}
+
+// unify merges same __typenames of variables and data, see [unifyTypenames]. it is done once, as the result has cycles.
+func (op *operation) unify() {
+	if !op.unified {
+		unifyTypenames(op.variables)
+		unifyTypenames(op.data)
+		op.unified = true
+	}
+}
+
+// readCaptures reads JSONL captures from r into operations, keyed by operation name.
+// lines that are not GraphQL requests, or have no `data` in response, are skipped with a warning to warn.
+func readCaptures(r io.Reader, operations map[string]*operation, warn io.Writer) error {
+	return helicon.ReadCaptures(r, func(line int, c helicon.Capture) error {
+		if err := addCapture(operations, c); err != nil {
+			_, _ = fmt.Fprintf(warn, "skipping line %d: %s\n", line, err)
+		}
+		return nil
+	})
+}
+
+func addCapture(operations map[string]*operation, c helicon.Capture) error {
+	queryID, name, err := c.Operation()
+	if err != nil {
+		return err
+	}
+	params, err := c.Params()
+	if err != nil {
+		return err
+	}
+	data, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	op := operations[name]
+	if op == nil {
+		op = &operation{name: name, variables: &node{}, features: &node{}, fieldToggles: &node{}, data: &node{}}
+		operations[name] = op
+	}
+	// later captures are newer, their query ID is the one to fall back to.
+	op.queryID = queryID
+	op.mutation = op.mutation || c.Mutation()
+	op.samples++
+	for _, sample := range []struct {
+		into *node
+		raw  json.RawMessage
+	}{
+		{op.variables, params["variables"]},
+		{op.features, params["features"]},
+		{op.fieldToggles, params["fieldToggles"]},
+		{op.data, data},
+	} {
+		if len(sample.raw) == 0 {
+			continue
+		}
+		value, err := decodeOrdered(sample.raw)
+		if err != nil {
+			return fmt.Errorf("invalid JSON in %s capture: %w", name, err)
+		}
+		sample.into.observe(value)
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type generator struct {
+	// prefix of every generated identifier, operation name by default.
+	prefix string
+	// "helicon." when generating outside of package helicon.
+	qualifier string
+
+	names  map[string]bool
+	bodies map[string]string
+	decls  []decl
+	fields map[*node][]goField
+	// Go type of every struct node, and the ones that are being generated right now.
+	typeNames map[*node]string
+	emitting  map[*node]bool
+}
+
+type decl struct {
+	name string
+	body string
+}
+
+type goField struct {
+	name string
+	typ  string
+}
+
+const heliconImport = "github.com/caner-cetin/helicon"
+
+// generate returns the Go source of op for package pkg. source is only used in the header comment.
+//
+// taken has names already declared in pkg, methods as "Type.Method", see [declaredNames]. generated names never clash
+// with them, if the top level ones would, they are prefixed with Gen.
+func generate(op *operation, pkg string, prefix string, source string, taken map[string]bool) ([]byte, error) {
+	g := &generator{
+		prefix: prefix + ident(op.name),
+		names:  map[string]bool{},
+		bodies: map[string]string{},
+		fields: map[*node][]goField{},
+
+		typeNames: map[*node]string{},
+		emitting:  map[*node]bool{},
+	}
+	if pkg != "helicon" {
+		g.qualifier = "helicon."
+	}
+	for _, candidate := range []string{g.prefix, "Gen" + g.prefix} {
+		if g.prefix = candidate; !slices.ContainsFunc(topLevelNames(candidate, g.qualifier == ""), func(name string) bool { return taken[name] }) {
+			break
+		}
+	}
+	for name := range taken {
+		g.names[name] = true
+	}
+	id := g.prefix
+	for _, name := range topLevelNames(id, g.qualifier == "") {
+		if taken[name] {
+			return nil, fmt.Errorf("%s is already declared in package %s, pick another -prefix", name, pkg)
+		}
+		g.names[name] = true
+	}
+	op.unify()
+	hasFeatures, hasFieldToggles := op.features.objects > 0, op.fieldToggles.objects > 0
+
+	var out bytes.Buffer
+	p := func(format string, args ...any) { _, _ = fmt.Fprintf(&out, format, args...) }
+	p("// Code generated by helicon-gen from %s. DO NOT EDIT.\n\npackage %s\n\n", source, pkg)
+	p("import (\n\"context\"\n\"encoding/json\"\n\"reflect\"\n")
+	if g.qualifier != "" {
+		p("%q\n", heliconImport)
+	}
+	p(")\n\n")
+
+	q := g.qualifier
+	p("// %sQueryID is the query ID of %s in the latest capture. current one is discovered through [%sQueryRegistry],\n", id, op.name, q)
+	p("// this is only used if discovery fails.\nconst %sQueryID = %q\n\n", id, op.queryID)
+
+	p("// %sRequest is everything %s is sent with.\ntype %sRequest struct {\nVariables %sVariables\n", id, op.name, id, id)
+	if hasFeatures {
+		p("Features %sFeatures\n", id)
+	}
+	if hasFieldToggles {
+		p("FieldToggles %sFieldToggles\n", id)
+	}
+	p("}\n\n")
+	p("// New%sRequest returns a request with default features and field toggles.\n", id)
+	p("func New%sRequest(variables %sVariables) %sRequest {\nreturn %sRequest{\nVariables: variables,\n", id, id, id, id)
+	if hasFeatures {
+		p("Features: New%sFeatures(),\n", id)
+	}
+	if hasFieldToggles {
+		p("FieldToggles: New%sFieldToggles(),\n", id)
+	}
+	p("}\n}\n\n")
+
+	operationType := "OperationQuery"
+	if op.mutation {
+		operationType = "OperationMutation"
+	}
+	p("// Get%s runs %s and returns its `data`.\n", id, op.name)
+	if q == "" {
+		p("func (h *Helicon) Get%s(ctx context.Context, request %sRequest, opts ...ExecuteOption) (*%sResponse, error) {\n", id, id, id)
+	} else {
+		p("func Get%s(ctx context.Context, h *helicon.Helicon, request %sRequest, opts ...helicon.ExecuteOption) (*%sResponse, error) {\n", id, id, id)
+	}
+	p("op := %sOperation{\nName: %q,\nQueryID: %sQueryID,\nType: %s%s,\nVariables: request.Variables,\n", q, op.name, id, q, operationType)
+	if hasFieldToggles {
+		p("FieldToggles: request.FieldToggles,\n")
+	}
+	p("}\n")
+	if hasFeatures {
+		p("features, err := %sFeatureSetFrom(request.Features)\nif err != nil {\nreturn nil, err\n}\nop.Features = features\n", q)
+	}
+	p("var response %sResponse\nif err := h.Execute(ctx, op, &response, opts...); err != nil {\nreturn nil, err\n}\nreturn &response, nil\n}\n\n", id)
+	p("func init() {\n%sResponseTypes[%q] = reflect.TypeFor[%sResponse]()\n}\n\n", q, op.name, id)
+
+	g.root(&out, op.variables, "Variables", fmt.Sprintf("variables of %s", op.name), true)
+	if hasFeatures {
+		g.root(&out, op.features, "Features", fmt.Sprintf("feature switches of %s", op.name), false)
+	}
+	if hasFieldToggles {
+		g.root(&out, op.fieldToggles, "FieldToggles", fmt.Sprintf("field toggles of %s", op.name), false)
+	}
+	g.root(&out, op.data, "Response", fmt.Sprintf("`data` of %s, inferred from %d captures", op.name, op.samples), false)
+
+	src := out.Bytes()
+	if !bytes.Contains(src[bytes.Index(src, []byte("const ")):], []byte("json.")) {
+		src = bytes.Replace(src, []byte("\"encoding/json\"\n"), nil, 1)
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return src, fmt.Errorf("generated invalid code for %s: %w", op.name, err)
+	}
+	return formatted, nil
+}
+
+// topLevelNames are the names generated for operation id, besides the types under its response. method is true if Get
+// is a method of Helicon.
+func topLevelNames(id string, method bool) []string {
+	names := []string{id + "QueryID", id + "Response"}
+	for _, name := range []string{"Request", "Variables", "Features", "FieldToggles"} {
+		names = append(names, id+name, "New"+id+name)
+	}
+	if method {
+		return append(names, "Helicon.Get"+id)
+	}
+	return append(names, "Get"+id)
+}
+
+// root writes the type of a top level node with every type it needs.
+// constructor with defaults is written for variables, features and field toggles, see [generator.defaults].
+func (g *generator) root(out *bytes.Buffer, n *node, name string, doc string, constantOnly bool) {
+	g.decls = nil
+	typeName := g.prefix + name
+	g.typeNames[n] = typeName
+	g.emitting[n] = true
+	body := g.body(n, name, false)
+	g.emitting[n] = false
+	_, _ = fmt.Fprintf(out, "// %s is %s.\ntype %s %s\n\n", typeName, doc, typeName, body)
+	if name != "Response" {
+		g.defaults(out, n, typeName, constantOnly)
+	}
+	for _, d := range slices.Backward(g.decls) {
+		_, _ = fmt.Fprintf(out, "type %s %s\n\n", d.name, d.body)
+	}
+}
+
+// defaults writes New<type> returning every scalar field set to what captures had. with constantOnly, fields that were
+// different between captures (tweet IDs, cursors...) are left empty, otherwise the value of the latest capture is used.
+func (g *generator) defaults(out *bytes.Buffer, n *node, typeName string, constantOnly bool) {
+	_, _ = fmt.Fprintf(out, "// New%s returns %s with values of captures.\nfunc New%s() %s {\nreturn %s{\n", typeName, typeName, typeName, typeName, typeName)
+	for i, key := range n.order {
+		f := n.fields[key].node
+		if !f.hasValue || (constantOnly && f.varies) {
+			continue
+		}
+		gf := g.fields[n][i]
+		value := literal(f.last)
+		if base, ok := strings.CutPrefix(gf.typ, "*"); ok {
+			value = fmt.Sprintf("%sPtr[%s](%s)", g.qualifier, base, value)
+		} else if gf.typ == "float64" {
+			value = fmt.Sprintf("float64(%s)", value)
+		}
+		if gf.typ == "json.RawMessage" {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "%s: %s,\n", gf.name, value)
+	}
+	_, _ = fmt.Fprintf(out, "}\n}\n\n")
+}
+
+func literal(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return strconv.Quote(v)
+	}
+	return "nil"
+}
+
+func (g *generator) goType(n *node, hint string, parent string, qualified bool) string {
+	switch n.kinds &^ kindNull {
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "int"
+	case kindFloat, kindInt | kindFloat:
+		return "float64"
+	case kindString:
+		return "string"
+	case kindArray:
+		if n.elem == nil || n.elem.kinds&^kindNull == 0 {
+			// never seen an element, anything we guess is wrong.
+			return "[]json.RawMessage"
+		}
+		elem := g.goType(n.elem, singular(hint), parent, qualified)
+		if n.elem.kinds&kindNull != 0 && pointerable(elem) {
+			elem = "*" + elem
+		}
+		return "[]" + elem
+	case kindObject:
+		if len(n.order) == 0 {
+			// never seen a field, same as above. raw message keeps whatever X starts sending.
+			return "json.RawMessage"
+		}
+		if n.isMap() {
+			return "map[string]" + g.goType(n.mapValues(), singular(hint), parent, qualified)
+		}
+		return g.structType(n, hint, parent, qualified)
+	}
+	// null only or mixed kinds.
+	return "json.RawMessage"
+}
+
+func (g *generator) structType(n *node, hint string, parent string, qualified bool) string {
+	if name, ok := g.typeNames[n]; ok {
+		return name
+	}
+	if typename := n.typename(); typename != "" {
+		// named before the body, so the type can refer to itself.
+		short := ident(typename)
+		name := g.reserve(short)
+		g.typeNames[n] = name
+		g.emitting[n] = true
+		body := g.body(n, short, true)
+		g.emitting[n] = false
+		g.decls = append(g.decls, decl{name, body})
+		return name
+	}
+	body := g.body(n, hint, false)
+	name, ok := g.bodies[body]
+	if !ok {
+		if qualified {
+			name = g.reserve(parent+hint, hint)
+		} else {
+			name = g.reserve(hint, parent+hint)
+		}
+		g.bodies[body] = name
+		g.decls = append(g.decls, decl{name, body})
+	}
+	g.typeNames[n] = name
+	return name
+}
+
+// body returns the struct type of n. hint is the short name of n, and qualified is true if fields should be named after it.
+func (g *generator) body(n *node, hint string, qualified bool) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	used := map[string]bool{}
+	fields := make([]goField, 0, len(n.order))
+	for _, key := range n.order {
+		f := n.fields[key]
+		name := ident(key)
+		for i := 2; used[name]; i++ {
+			name = ident(key) + strconv.Itoa(i)
+		}
+		used[name] = true
+		typ := g.goType(f.node, name, hint, qualified)
+		optional := f.count < n.objects || f.node.kinds&kindNull != 0
+		// f refers back to a type that is being generated, it can only be there through a pointer.
+		if (optional || g.emitting[f.node]) && pointerable(typ) {
+			typ = "*" + typ
+		}
+		tag := key
+		if optional {
+			tag += ",omitempty"
+		}
+		fields = append(fields, goField{name, typ})
+		_, _ = fmt.Fprintf(&b, "%s %s `json:%q`\n", name, typ, tag)
+	}
+	b.WriteString("}")
+	g.fields[n] = fields
+	return b.String()
+}
+
+// reserve returns the first candidate that is not taken, prefixed. if every one is taken, first one is numbered.
+func (g *generator) reserve(candidates ...string) string {
+	for _, candidate := range candidates {
+		if name := g.prefix + candidate; !g.names[name] {
+			g.names[name] = true
+			return name
+		}
+	}
+	for i := 2; ; i++ {
+		if name := g.prefix + candidates[0] + strconv.Itoa(i); !g.names[name] {
+			g.names[name] = true
+			return name
+		}
+	}
+}
+
+func pointerable(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "json.RawMessage"
+}
+
+// ident converts a JSON key into an exported Go identifier, `rest_id` => RestId, `focalTweetId` => FocalTweetId.
+func ident(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit(rune(name[0])) {
+		return "N" + name
+	}
+	return name
+}
+
+// singular names elements of an array, Entries => Entry, Urls => Url.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") &&
+		!strings.HasSuffix(name, "us") && !strings.HasSuffix(name, "is"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// snake converts an operation name to a file name, TweetDetail => tweet_detail.
+func snake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(name[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
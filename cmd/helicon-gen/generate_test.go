@@ -0,0 +1,143 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const captures = `
+{"method":"GET","url":"https://x.com/i/api/graphql/abc/TweetDetail?variables=%7B%22focalTweetId%22%3A%221%22%2C%22withVoice%22%3Atrue%7D&features=%7B%22a%22%3Atrue%7D","response":{"data":{"tweet":{"__typename":"Tweet","rest_id":"1","core":{"user_results":{"result":{"__typename":"User","rest_id":"2","legacy":{"name":"x"}}}},"views":{"count":"5"},"quoted_status_result":{"result":{"__typename":"Tweet","rest_id":"3","core":{"user_results":{"result":{"__typename":"User","rest_id":"4","legacy":{"name":"y","location":"z"}}}},"entities":{"urls":[]}}}}}}}
+{"method":"GET","url":"https://x.com/i/api/graphql/def/TweetDetail?variables=%7B%22focalTweetId%22%3A%229%22%2C%22withVoice%22%3Atrue%7D&features=%7B%22a%22%3Afalse%7D","response":"{\"data\":{\"tweet\":{\"__typename\":\"Tweet\",\"rest_id\":\"9\",\"core\":{\"user_results\":{\"result\":{\"__typename\":\"User\",\"rest_id\":\"2\",\"legacy\":{\"name\":\"x\"}}}},\"views\":null,\"tipjar\":{}}}}"}
+{"method":"GET","url":"https://x.com/home","response":{}}
+`
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	operations := map[string]*operation{}
+	if err := readCaptures(strings.NewReader(captures), operations, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	op := operations["TweetDetail"]
+	if len(operations) != 1 || op.samples != 2 || op.queryID != "def" {
+		t.Fatalf("unexpected operations %v", operations)
+	}
+	src, err := generate(op, "helicon", "", "captures.jsonl", nil)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, src)
+	}
+	code := string(src)
+	for _, want := range []string{
+		`const TweetDetailQueryID = "def"`,
+		"func (h *Helicon) GetTweetDetail(ctx context.Context, request TweetDetailRequest, opts ...ExecuteOption) (*TweetDetailResponse, error)",
+		// same __typename is one type, no matter where it is.
+		"type TweetDetailTweet struct",
+		"type TweetDetailUser struct",
+		// quoted tweet is a Tweet inside a Tweet.
+		"Result *TweetDetailTweet `json:\"result\"`",
+		// missing or null in one of the captures.
+		"QuotedStatusResult *TweetDetailTweetQuotedStatusResult `json:\"quoted_status_result,omitempty\"`",
+		"Views              *TweetDetailTweetViews",
+		"Location *string `json:\"location,omitempty\"`",
+		// nothing seen, nothing guessed.
+		"Urls []json.RawMessage",
+		"Tipjar             json.RawMessage",
+		// tweet ID differs between captures, so it has no default. features default to the latest capture.
+		"return TweetDetailVariables{\n\t\tWithVoice: true,\n\t}",
+		"return TweetDetailFeatures{\n\t\tA: false,\n\t}",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not have %q\n%s", want, code)
+		}
+	}
+	if strings.Count(code, "type TweetDetailUserLegacy struct") != 1 {
+		t.Errorf("user legacy must be generated once\n%s", code)
+	}
+
+	external, err := generate(op, "tweets", "X", "captures.jsonl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(external), "func GetXTweetDetail(ctx context.Context, h *helicon.Helicon, request XTweetDetailRequest, opts ...helicon.ExecuteOption)") {
+		t.Errorf("outside of package helicon, function must take the client\n%s", external)
+	}
+}
+
+func TestGenerate_Compiles(t *testing.T) {
+	t.Parallel()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	operations := map[string]*operation{}
+	if err := readCaptures(strings.NewReader(captures), operations, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	op := operations["TweetDetail"]
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	taken, err := declaredNames(root, "helicon", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !taken["TweetDetailRequest"] || !taken["Helicon.GetTweetDetails"] {
+		t.Fatalf("handwritten names of package helicon are not found: %v", taken)
+	}
+
+	// a copy of package helicon, generated files are vetted in it and in a package next to it.
+	dir := t.TempDir()
+	paths, err := filepath.Glob(filepath.Join(root, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if (!strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go")) && name != "go.mod" && name != "go.sum" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "tweets"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		pkg    string
+		prefix string
+		path   string
+	}{
+		{"helicon", "", "tweet_detail_gen.go"},
+		{"helicon", "X", "x_tweet_detail_gen.go"},
+		{"tweets", "", filepath.Join("tweets", "tweet_detail_gen.go")},
+		{"tweets", "X", filepath.Join("tweets", "x_tweet_detail_gen.go")},
+	}
+	for _, c := range cases {
+		var names map[string]bool
+		if c.pkg == "helicon" {
+			names = taken
+		}
+		src, err := generate(op, c.pkg, c.prefix, "captures.jsonl", names)
+		if err != nil {
+			t.Fatalf("%s\n%s", err, src)
+		}
+		if err := os.WriteFile(filepath.Join(dir, c.path), src, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vet := exec.Command(goTool, "vet", "./...")
+	vet.Dir = dir
+	vet.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOPROXY=off")
+	if out, err := vet.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not compile: %s\n%s", err, out)
+	}
+}
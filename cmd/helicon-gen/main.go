@@ -0,0 +1,164 @@
+// Command helicon-gen generates typed wrappers of GraphQL operations from captured traffic of the web client.
+//
+// Input is JSONL, one request/response pair per line, see [helicon.Capture]:
+//
+//	{"method":"GET","url":"https://x.com/i/api/graphql/<queryId>/UserByScreenName?variables=...&features=...","response":{"data":{...}}}
+//
+// For every operation found, a <operation>_gen.go file is written with
+//
+//   - <Operation>Variables, <Operation>Features and <Operation>FieldToggles, with constructors that default to captured values
+//   - <Operation>Response and named types for everything under it. objects with the same __typename share one type,
+//     fields that were missing or null in some captures are pointers
+//   - Get<Operation>, which runs the operation through [helicon.Helicon.Execute]
+//   - registration of <Operation>Response in [helicon.ResponseTypes], so helicon-drift can check captures against it
+//
+// Capture the same operation a few times, from different tweets, users and pages, so optional fields show up as optional.
+//
+// Usage:
+//
+//	helicon-gen [-out dir] [-pkg name] [-prefix prefix] [-op TweetDetail,UserByScreenName] captures.jsonl...
+//
+// Reads stdin if no file is given. Generated names start with the operation name. Names already declared in the output
+// package are not reused, if top level ones would clash (TweetDetailRequest already exists in package helicon) they
+// start with Gen, use -prefix to pick something else.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func main() {
+	out := flag.String("out", ".", "directory to write generated files into")
+	pkg := flag.String("pkg", "helicon", "package name of generated files")
+	prefix := flag.String("prefix", "", "prefix of every generated identifier")
+	only := flag.String("op", "", "comma separated operations to generate, all of them if empty")
+	flag.Parse()
+	if err := run(flag.Args(), *out, *pkg, *prefix, *only); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "helicon-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(inputs []string, out string, pkg string, prefix string, only string) error {
+	operations := map[string]*operation{}
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, input := range inputs {
+		if err := readInput(input, operations); err != nil {
+			return err
+		}
+	}
+	var wanted []string
+	if only != "" {
+		wanted = strings.Split(only, ",")
+	}
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		if wanted == nil || slices.Contains(wanted, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no GraphQL operations found in %s", strings.Join(inputs, ", "))
+	}
+	slices.Sort(names)
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = snake(name) + "_gen.go"
+	}
+	// files that are about to be regenerated do not count, their names are generated again.
+	taken, err := declaredNames(out, pkg, files)
+	if err != nil {
+		return err
+	}
+	source := strings.Join(inputs, ", ")
+	for i, name := range names {
+		src, err := generate(operations[name], pkg, prefix, source, taken)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(out, files[i])
+		if err := os.WriteFile(path, src, 0o644); err != nil { //nolint:gosec // generated source is meant to be readable
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %d captures => %s\n", name, operations[name].samples, path)
+	}
+	return nil
+}
+
+// declaredNames returns every top level name declared in package pkg in dir, methods as "Type.Method". test files and
+// skipped ones are not read.
+func declaredNames(dir string, pkg string, skip []string) (map[string]bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	names := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || slices.Contains(skip, filepath.Base(path)) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if file.Name.Name != pkg {
+			continue
+		}
+		for _, d := range file.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) == 0 {
+					names[d.Name.Name] = true
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					names[ident.Name+"."+d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						names[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							names[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+func readInput(input string, operations map[string]*operation) error {
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("failed to open captures: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	if err := readCaptures(r, operations, os.Stderr); err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+	return nil
+}
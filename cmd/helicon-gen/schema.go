@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode"
+)
+
+// object is a decoded JSON object that remembers key order, so generated structs follow the order X sends fields in.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func decodeOrdered(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		// nil, bool, json.Number or string
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := &object{values: map[string]any{}}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := obj.values[key]; !dup {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		_, err = dec.Token()
+		return obj, err
+	case '[':
+		array := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token()
+		return array, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+type kind uint8
+
+const (
+	kindNull kind = 1 << iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindArray
+	kindObject
+)
+
+// node is the inferred shape of every value seen at one place of a response, merged across samples.
+type node struct {
+	kinds kind
+
+	// objects is how many objects were seen here, a field seen fewer times than this is optional.
+	objects   int
+	fields    map[string]*field
+	order     []string
+	typenames map[string]bool
+
+	elem *node
+
+	// first and last scalar seen, and whether scalars here were ever different. used for defaults.
+	hasValue    bool
+	first, last any
+	varies      bool
+
+	mapValue *node
+}
+
+type field struct {
+	node  *node
+	count int
+}
+
+func (n *node) observe(v any) {
+	switch v := v.(type) {
+	case nil:
+		n.kinds |= kindNull
+		return
+	case bool:
+		n.kinds |= kindBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			n.kinds |= kindInt
+		} else {
+			n.kinds |= kindFloat
+		}
+	case string:
+		n.kinds |= kindString
+	case []any:
+		n.kinds |= kindArray
+		if n.elem == nil {
+			n.elem = &node{}
+		}
+		for _, e := range v {
+			n.elem.observe(e)
+		}
+		return
+	case *object:
+		n.kinds |= kindObject
+		n.objects++
+		for _, key := range v.keys {
+			f := n.field(key)
+			f.count++
+			f.node.observe(v.values[key])
+		}
+		if typename, ok := v.values["__typename"].(string); ok {
+			n.addTypename(typename)
+		}
+		return
+	}
+	n.track(v, false)
+}
+
+func (n *node) track(v any, varies bool) {
+	if !n.hasValue {
+		n.hasValue, n.first = true, v
+	} else if n.first != v {
+		varies = true
+	}
+	n.last = v
+	n.varies = n.varies || varies
+}
+
+func (n *node) field(key string) *field {
+	if n.fields == nil {
+		n.fields = map[string]*field{}
+	}
+	f, ok := n.fields[key]
+	if !ok {
+		f = &field{node: &node{}}
+		n.fields[key] = f
+		n.order = append(n.order, key)
+	}
+	return f
+}
+
+func (n *node) addTypename(typename string) {
+	if n.typenames == nil {
+		n.typenames = map[string]bool{}
+	}
+	n.typenames[typename] = true
+}
+
+// merge adds everything seen at src to n, as if src's samples were seen at n.
+func (n *node) merge(src *node) {
+	n.kinds |= src.kinds
+	n.objects += src.objects
+	for typename := range src.typenames {
+		n.addTypename(typename)
+	}
+	for _, key := range src.order {
+		f := n.field(key)
+		f.count += src.fields[key].count
+		f.node.merge(src.fields[key].node)
+	}
+	if src.elem != nil {
+		if n.elem == nil {
+			n.elem = &node{}
+		}
+		n.elem.merge(src.elem)
+	}
+	if src.hasValue {
+		n.track(src.first, src.varies)
+		n.last = src.last
+	}
+}
+
+// typename is the __typename every object seen here had, empty if there were none or more than one.
+func (n *node) typename() string {
+	if n.kinds&^kindNull != kindObject || len(n.typenames) != 1 {
+		return ""
+	}
+	for typename := range n.typenames {
+		return typename
+	}
+	return ""
+}
+
+// isMap is true for objects keyed by IDs, `{"1234": {...}, "5678": {...}}`.
+func (n *node) isMap() bool {
+	if len(n.order) == 0 {
+		return false
+	}
+	for _, key := range n.order {
+		if key == "" || !unicode.IsDigit(rune(key[0])) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *node) mapValues() *node {
+	if n.mapValue == nil {
+		n.mapValue = &node{}
+		for _, key := range n.order {
+			n.mapValue.merge(n.fields[key].node)
+		}
+	}
+	return n.mapValue
+}
+
+// unifyTypenames merges every object with the same __typename into one node, wherever it appears under root.
+// a User is a User whether it is the author of the focal tweet or of a reply three levels down, so it is generated once
+// with every field seen in any of those places. after this, tree under root can have cycles (quoted tweets are Tweets).
+func unifyTypenames(root *node) {
+	groups := map[string][]*node{}
+	var order []string
+	var collect func(n *node)
+	collect = func(n *node) {
+		if typename := n.typename(); typename != "" {
+			if _, ok := groups[typename]; !ok {
+				order = append(order, typename)
+			}
+			groups[typename] = append(groups[typename], n)
+		}
+		for _, key := range n.order {
+			collect(n.fields[key].node)
+		}
+		if n.elem != nil {
+			collect(n.elem)
+		}
+	}
+	collect(root)
+
+	unified := map[string]*node{}
+	for _, typename := range order {
+		u := &node{}
+		for _, n := range groups[typename] {
+			u.merge(n)
+		}
+		unified[typename] = u
+	}
+	visited := map[*node]bool{}
+	var link func(n *node)
+	link = func(n *node) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		for _, key := range n.order {
+			f := n.fields[key]
+			if u, ok := unified[f.node.typename()]; ok {
+				f.node = u
+			}
+			link(f.node)
+		}
+		if n.elem != nil {
+			if u, ok := unified[n.elem.typename()]; ok {
+				n.elem = u
+			}
+			link(n.elem)
+		}
+	}
+	link(root)
+}
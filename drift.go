@@ -0,0 +1,435 @@
+package helicon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+type DriftKind string
+
+const (
+	// DriftUnknownField is a field X sends that Go type has no place for, it is dropped while decoding.
+	DriftUnknownField DriftKind = "unknown field"
+	// DriftTypeMismatch is a field X sends with a different JSON type than Go type expects.
+	DriftTypeMismatch DriftKind = "type mismatch"
+	// DriftMissingField is a field Go type expects (not a pointer, not omitempty) that X did not send.
+	DriftMissingField DriftKind = "missing field"
+	// DriftUntyped is an object or array decoded into an interface{}, like `Urls []interface{}`. nothing is lost, but no one
+	// is going to read it either.
+	DriftUntyped DriftKind = "untyped"
+)
+
+// Drift is one difference between a response and the Go type it is decoded into.
+type Drift struct {
+	Kind DriftKind `json:"kind"`
+	// JSON path, array elements are `[]` and map values are `*`, so drifts of every element are reported as one.
+	// `data.threaded_conversation_with_injections_v2.instructions[].entries[].content`
+	Path string `json:"path"`
+	// JSON type Go type expects, empty for unknown fields.
+	Expected string `json:"expected,omitempty"`
+	// JSON type in response, empty for missing fields.
+	Got string `json:"got,omitempty"`
+	// how many times this was seen.
+	Count int `json:"count"`
+}
+
+func (d Drift) String() string {
+	switch d.Kind {
+	case DriftUnknownField, DriftUntyped:
+		return fmt.Sprintf("%s %s (%s)", d.Kind, d.Path, d.Got)
+	case DriftMissingField:
+		return fmt.Sprintf("%s %s (%s)", d.Kind, d.Path, d.Expected)
+	}
+	return fmt.Sprintf("%s %s: expected %s, got %s", d.Kind, d.Path, d.Expected, d.Got)
+}
+
+// DriftError is returned from [Helicon.Execute] in strict mode, see [Helicon.StrictDecoding].
+type DriftError struct {
+	Operation string
+	Drifts    []Drift
+}
+
+func (e *DriftError) Error() string {
+	drifts := make([]string, 0, len(e.Drifts))
+	for _, drift := range e.Drifts {
+		drifts = append(drifts, drift.String())
+	}
+	return fmt.Sprintf("%s response does not match its type: %s", e.Operation, strings.Join(drifts, "; "))
+}
+
+// ResponseTypes maps operation names to the type their `data` is decoded into, so recorded traffic can be checked
+// against them without running the operation, see [DriftReport.CheckCapture]. helicon-gen registers generated types here.
+var ResponseTypes = map[string]reflect.Type{
+	"TweetDetail": reflect.TypeOf(TweetDetailResponse{}.Data),
+}
+
+// DetectDrift walks data alongside t and returns every field that does not fit, see [DriftKind].
+// Types that decode themselves ([json.Unmarshaler], [json.RawMessage]) are not looked into, unless they tell which type
+// they decode through, like [TweetResult] does.
+func DetectDrift(data []byte, t reflect.Type) ([]Drift, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	d := driftDetector{index: map[string]int{}}
+	d.walk("data", value, t)
+	return d.drifts, nil
+}
+
+// strictDecode returns [DriftError] if data has unknown fields or type mismatches for t.
+func strictDecode(operation string, data []byte, t reflect.Type) error {
+	drifts, err := DetectDrift(data, t)
+	if err != nil {
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+	drifts = slices.DeleteFunc(drifts, func(drift Drift) bool {
+		return drift.Kind != DriftUnknownField && drift.Kind != DriftTypeMismatch
+	})
+	if len(drifts) > 0 {
+		return &DriftError{Operation: operation, Drifts: drifts}
+	}
+	return nil
+}
+
+type driftDetector struct {
+	drifts []Drift
+	// Kind+Path+Got => index in drifts
+	index map[string]int
+}
+
+func (d *driftDetector) report(drift Drift) {
+	key := string(drift.Kind) + "\x00" + drift.Path + "\x00" + drift.Got
+	if i, ok := d.index[key]; ok {
+		d.drifts[i].Count++
+		return
+	}
+	drift.Count = 1
+	d.index[key] = len(d.drifts)
+	d.drifts = append(d.drifts, drift)
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// drifter is a [json.Unmarshaler] that decodes through another type, drifts are looked for in that type.
+type drifter interface {
+	driftType() reflect.Type
+}
+
+var drifterType = reflect.TypeFor[drifter]()
+
+func (d *driftDetector) walk(path string, value any, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(drifterType) {
+		t = reflect.New(t).Interface().(drifter).driftType()
+	}
+	if value == nil || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	mismatch := func() {
+		d.report(Drift{Kind: DriftTypeMismatch, Path: path, Expected: jsonTypeOf(t), Got: jsonType(value)})
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		switch value.(type) {
+		case map[string]any, []any:
+			d.report(Drift{Kind: DriftUntyped, Path: path, Got: jsonType(value)})
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		fields := jsonFieldsOf(t)
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			f, ok := fields.lookup(key)
+			if !ok {
+				d.report(Drift{Kind: DriftUnknownField, Path: path + "." + key, Got: jsonType(object[key])})
+				continue
+			}
+			d.walk(path+"."+key, object[key], f.typ)
+		}
+		for _, f := range fields.list {
+			if _, ok := object[f.name]; !ok && f.required && !fields.hasFold(object, f.name) {
+				d.report(Drift{Kind: DriftMissingField, Path: path + "." + f.name, Expected: jsonTypeOf(f.typ)})
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			d.walk(path+".*", object[key], t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		if _, ok := value.(string); ok && t.Elem().Kind() == reflect.Uint8 {
+			// []byte is base64
+			return
+		}
+		array, ok := value.([]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		for _, element := range array {
+			d.walk(path+"[]", element, t.Elem())
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			mismatch()
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, ok := value.(json.Number); !ok {
+			mismatch()
+		} else if _, err := number.Int64(); err != nil {
+			d.report(Drift{Kind: DriftTypeMismatch, Path: path, Expected: "integer", Got: "number"})
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			mismatch()
+		}
+	default:
+		mismatch()
+	}
+}
+
+// jsonType is the JSON type of a value decoded into any.
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonTypeOf is the JSON type t decodes from, Go type names are useless for the anonymous structs of hand-written types.
+func jsonTypeOf(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Interface:
+		return "any"
+	}
+	return t.String()
+}
+
+type jsonField struct {
+	name string
+	typ  reflect.Type
+	// not a pointer, not omitempty or omitzero, and not promoted from an embedded pointer.
+	required bool
+}
+
+type jsonFields struct {
+	list   []jsonField
+	byName map[string]jsonField
+}
+
+// lookup finds the field encoding/json would decode key into, exact name first, then case-insensitive.
+func (f *jsonFields) lookup(key string) (jsonField, bool) {
+	if field, ok := f.byName[key]; ok {
+		return field, true
+	}
+	for _, field := range f.list {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+func (f *jsonFields) hasFold(object map[string]any, name string) bool {
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+var jsonFieldsCache sync.Map
+
+func jsonFieldsOf(t reflect.Type) *jsonFields {
+	if cached, ok := jsonFieldsCache.Load(t); ok {
+		return cached.(*jsonFields)
+	}
+	fields := &jsonFields{byName: map[string]jsonField{}}
+	collectJSONFields(t, fields, false)
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func collectJSONFields(t reflect.Type, fields *jsonFields, optional bool) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			embedded := sf.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				// fields of embedded structs are promoted, like encoding/json does. nil embedded pointer is fine,
+				// none of its fields are required.
+				collectJSONFields(embedded, fields, optional || sf.Type.Kind() == reflect.Pointer)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		optional := optional || sf.Type.Kind() == reflect.Pointer || sf.Type.Kind() == reflect.Interface ||
+			slices.Contains(strings.Split(options, ","), "omitempty") || slices.Contains(strings.Split(options, ","), "omitzero")
+		field := jsonField{name: name, typ: sf.Type, required: !optional}
+		if _, ok := fields.byName[name]; ok {
+			continue
+		}
+		fields.byName[name] = field
+		fields.list = append(fields.list, field)
+	}
+}
+
+// DriftReport collects drifts of many responses per operation, to check recorded traffic against response types
+// before X breaks them for users. helicon-drift builds one from captures.
+type DriftReport struct {
+	Operations map[string]*OperationDrift `json:"operations"`
+}
+
+// OperationDrift is every drift seen in Captures responses of one operation.
+type OperationDrift struct {
+	Captures int     `json:"captures"`
+	Drifts   []Drift `json:"drifts"`
+}
+
+// Check adds drifts of data, decoded into t, to the report.
+func (r *DriftReport) Check(operation string, data []byte, t reflect.Type) error {
+	drifts, err := DetectDrift(data, t)
+	if err != nil {
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+	if r.Operations == nil {
+		r.Operations = map[string]*OperationDrift{}
+	}
+	report := r.Operations[operation]
+	if report == nil {
+		report = &OperationDrift{}
+		r.Operations[operation] = report
+	}
+	report.Captures++
+	for _, drift := range drifts {
+		i := slices.IndexFunc(report.Drifts, func(seen Drift) bool {
+			return seen.Kind == drift.Kind && seen.Path == drift.Path && seen.Got == drift.Got
+		})
+		if i < 0 {
+			report.Drifts = append(report.Drifts, drift)
+		} else {
+			report.Drifts[i].Count += drift.Count
+		}
+	}
+	return nil
+}
+
+// CheckCapture checks response of c against type of its operation in [ResponseTypes].
+// returns false if there is no type to check against.
+func (r *DriftReport) CheckCapture(c Capture) (bool, error) {
+	_, operation, err := c.Operation()
+	if err != nil {
+		return false, err
+	}
+	t, ok := ResponseTypes[operation]
+	if !ok {
+		return false, nil
+	}
+	data, err := c.Data()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", operation, err)
+	}
+	return true, r.Check(operation, data, t)
+}
+
+// HasDrift is true if any response had any drift.
+func (r *DriftReport) HasDrift() bool {
+	for _, operation := range r.Operations {
+		if len(operation.Drifts) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes a human-readable report, operations and drifts in alphabetical order.
+func (r *DriftReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range slices.Sorted(maps.Keys(r.Operations)) {
+		operation := r.Operations[name]
+		_, _ = fmt.Fprintf(tw, "%s: %d drifts in %d captures\n", name, len(operation.Drifts), operation.Captures)
+		drifts := slices.Clone(operation.Drifts)
+		slices.SortFunc(drifts, func(a, b Drift) int {
+			return strings.Compare(a.Path+string(a.Kind), b.Path+string(b.Kind))
+		})
+		for _, drift := range drifts {
+			detail := drift.Got
+			switch drift.Kind {
+			case DriftMissingField:
+				detail = drift.Expected
+			case DriftTypeMismatch:
+				detail = fmt.Sprintf("expected %s, got %s", drift.Expected, drift.Got)
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\t×%d\n", drift.Kind, drift.Path, detail, drift.Count)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+	return nil
+}
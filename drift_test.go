@@ -0,0 +1,137 @@
+package helicon_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/caner-cetin/helicon"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type driftBase struct {
+	ID string `json:"id"`
+}
+
+type driftTweet struct {
+	driftBase
+	Count    int            `json:"count"`
+	Urls     []interface{}  `json:"urls"`
+	Tipjar   struct{}       `json:"tipjar"`
+	Optional *string        `json:"optional"`
+	Omitted  string         `json:"omitted,omitempty"`
+	Counts   map[string]int `json:"counts"`
+	Required string         `json:"required"`
+}
+
+func TestDetectDrift(t *testing.T) {
+	t.Parallel()
+	data := []byte(`{"id":"1","count":"5","urls":[{"url":"a"},{"url":"b"}],"tipjar":{"is_enabled":true},"counts":{"a":1,"b":1.5},"brand_new":[1]}`)
+	drifts, err := helicon.DetectDrift(data, reflect.TypeFor[driftTweet]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []helicon.Drift{
+		{Kind: helicon.DriftUnknownField, Path: "data.brand_new", Got: "array", Count: 1},
+		{Kind: helicon.DriftTypeMismatch, Path: "data.count", Expected: "integer", Got: "string", Count: 1},
+		{Kind: helicon.DriftTypeMismatch, Path: "data.counts.*", Expected: "integer", Got: "number", Count: 1},
+		{Kind: helicon.DriftUnknownField, Path: "data.tipjar.is_enabled", Got: "bool", Count: 1},
+		{Kind: helicon.DriftUntyped, Path: "data.urls[]", Got: "object", Count: 2},
+		{Kind: helicon.DriftMissingField, Path: "data.required", Expected: "string", Count: 1},
+	}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("unexpected drifts\n got %+v\nwant %+v", drifts, want)
+	}
+}
+
+func TestDriftReport(t *testing.T) {
+	t.Parallel()
+	captures := `{"method":"GET","url":"https://x.com/i/api/graphql/q/TweetDetail?variables=%7B%7D","response":{"data":{"threaded_conversation_with_injections_v2":{"instructions":[{"type":"TimelineClearCache","brand_new":1}]}}}}
+{"method":"GET","url":"https://x.com/i/api/graphql/q/TweetDetail?variables=%7B%7D","response":"{\"data\":{\"threaded_conversation_with_injections_v2\":{\"instructions\":[{\"type\":\"TimelineClearCache\",\"brand_new\":2}]}}}"}
+{"method":"GET","url":"https://x.com/i/api/graphql/q/SomethingElse","response":{"data":{}}}`
+	var report helicon.DriftReport
+	checked := 0
+	err := helicon.ReadCaptures(strings.NewReader(captures), func(_ int, c helicon.Capture) error {
+		ok, err := report.CheckCapture(c)
+		if ok {
+			checked++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation := report.Operations["TweetDetail"]
+	if checked != 2 || operation.Captures != 2 || !report.HasDrift() {
+		t.Fatalf("unexpected report %+v", operation)
+	}
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "unknown field  data.threaded_conversation_with_injections_v2.instructions[].brand_new  number  ×2") {
+		t.Errorf("unexpected report\n%s", text.String())
+	}
+}
+
+func TestDetectDrift_TweetDetailFixture(t *testing.T) {
+	t.Parallel()
+	body, err := os.ReadFile("testdata/tweet_detail.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err := helicon.DetectDrift(response.Data, helicon.ResponseTypes["TweetDetail"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, drift := range drifts {
+		t.Errorf("%s ×%d", drift, drift.Count)
+	}
+}
+
+func TestDetectDrift_TweetResults(t *testing.T) {
+	t.Parallel()
+	// TweetResult decodes itself, drifts must be found in tweets and users in it anyway, wrapped or not.
+	data := []byte(`{"tweets":[
+		{"result":{"__typename":"Tweet","rest_id":"1","legacy":{"entities":{"urls":[{"url":"a","expanded_url":"b","display_url":"c","indices":[0,1],"brand_new":1}]}},
+			"core":{"user_results":{"result":{"rest_id":"2","tipjar_settings":{"is_enabled":"yes","bitcoin_handle":"x"}}}}}},
+		{"result":{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"3","legacy":{"entities":{"urls":[{"url":"a","indices":"0,1"}]}}}}},
+		{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"gone"}}}}]}`)
+	drifts, err := helicon.DetectDrift(data, reflect.TypeFor[struct {
+		Tweets []helicon.TweetResults `json:"tweets"`
+	}]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"unknown field data.tweets[].result.legacy.entities.urls[].brand_new (number)":                                      true,
+		"type mismatch data.tweets[].result.core.user_results.result.tipjar_settings.is_enabled: expected bool, got string": true,
+		"unknown field data.tweets[].result.core.user_results.result.tipjar_settings.bitcoin_handle (string)":               true,
+		"type mismatch data.tweets[].result.tweet.legacy.entities.urls[].indices: expected array, got string":               true,
+	}
+	for _, drift := range drifts {
+		if drift.Kind == helicon.DriftMissingField {
+			continue
+		}
+		if !want[drift.String()] {
+			t.Errorf("unexpected drift %s", drift)
+		}
+		delete(want, drift.String())
+	}
+	for drift := range want {
+		t.Errorf("missing drift %s", drift)
+	}
+	for _, drift := range drifts {
+		// tombstones and TweetWithVisibilityResults have none of the tweet fields at the top.
+		if drift.Kind == helicon.DriftMissingField && (drift.Path == "data.tweets[].result.rest_id" || drift.Path == "data.tweets[].result.legacy") {
+			t.Errorf("fields of tweets must not be required in every result, got %s", drift)
+		}
+	}
+}
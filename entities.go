@@ -0,0 +1,303 @@
+package helicon
+
+import (
+	"cmp"
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Entities are the parts of tweet text that are more than text. Indices are of code points, not bytes, of the text
+// with `&amp;`, `&lt;` and `&gt;` unescaped, see [Tweet.PlainText].
+type Entities struct {
+	Hashtags []HashtagEntity `json:"hashtags,omitempty"`
+	// cashtags, $TSLA.
+	Symbols      []HashtagEntity   `json:"symbols,omitempty"`
+	Timestamps   []TimestampEntity `json:"timestamps,omitempty"`
+	Urls         []URLEntity       `json:"urls,omitempty"`
+	UserMentions []MentionEntity   `json:"user_mentions,omitempty"`
+	// t.co link of attached media, every media of a tweet shares it. only the first media is here, see [Tweet.Media].
+	Media []MediaEntity `json:"media,omitempty"`
+}
+
+type HashtagEntity struct {
+	Text    string `json:"text"`
+	Indices [2]int `json:"indices"`
+}
+
+// TimestampEntity is a time in a video, "skip to 1:23".
+type TimestampEntity struct {
+	Seconds int    `json:"seconds"`
+	Text    string `json:"text"`
+	Indices [2]int `json:"indices"`
+}
+
+// URLEntity is a t.co link in text, Url is what is in the text.
+type URLEntity struct {
+	Url         string `json:"url"`
+	ExpandedUrl string `json:"expanded_url"`
+	DisplayUrl  string `json:"display_url"`
+	Indices     [2]int `json:"indices"`
+}
+
+// MediaEntity is the t.co link of attached media, with which media it is.
+type MediaEntity struct {
+	URLEntity
+	IdStr         string `json:"id_str,omitempty"`
+	MediaKey      string `json:"media_key,omitempty"`
+	MediaUrlHttps string `json:"media_url_https,omitempty"`
+	Type          string `json:"type,omitempty"`
+}
+
+type MentionEntity struct {
+	IdStr      string `json:"id_str"`
+	Name       string `json:"name"`
+	ScreenName string `json:"screen_name"`
+	Indices    [2]int `json:"indices"`
+}
+
+// PlainText is the text web client shows: without reply mentions at the start and media link at the end, with t.co links
+// expanded and HTML unescaped.
+func (t *Tweet) PlainText() string {
+	return t.render(formatPlain)
+}
+
+// Markdown is [Tweet.PlainText] with links, mentions, hashtags and cashtags linked, and rest of the text escaped.
+func (t *Tweet) Markdown() string {
+	return t.render(formatMarkdown)
+}
+
+// HTML is [Tweet.PlainText] with links, mentions, hashtags and cashtags linked, and rest of the text escaped. Only
+// http and https links are linked, it is safe to put into a page as is.
+func (t *Tweet) HTML() string {
+	return t.render(formatHTML)
+}
+
+// Markdown is the text with its links.
+func (r RichText) Markdown() string {
+	return r.render(formatMarkdown)
+}
+
+// HTML is the text with its links, escaped, see [Tweet.HTML].
+func (r RichText) HTML() string {
+	return r.render(formatHTML)
+}
+
+func (t *Tweet) render(format textFormat) string {
+	text := []rune(unescapeTweetText(t.Text))
+	start, end := t.DisplayTextRange[0], t.DisplayTextRange[1]
+	slice := func(indices [2]int) string {
+		if indices[0] < 0 || indices[0] > indices[1] || indices[1] > len(text) {
+			return ""
+		}
+		return string(text[indices[0]:indices[1]])
+	}
+	var entities []textEntity
+	for _, u := range t.Entities.Urls {
+		expanded := cmp.Or(u.ExpandedUrl, u.Url)
+		entities = append(entities, textEntity{u.Indices, cmp.Or(u.DisplayUrl, expanded), expanded, expanded})
+	}
+	for _, media := range t.Entities.Media {
+		// media is shown below the text, its link is not.
+		entities = append(entities, textEntity{indices: media.Indices})
+	}
+	// shown as they are written, @Bob is still @Bob if screen name is bob.
+	for _, mention := range t.Entities.UserMentions {
+		shown := cmp.Or(slice(mention.Indices), "@"+mention.ScreenName)
+		entities = append(entities, textEntity{mention.Indices, shown, "https://x.com/" + url.PathEscape(mention.ScreenName), shown})
+	}
+	for _, hashtag := range t.Entities.Hashtags {
+		shown := cmp.Or(slice(hashtag.Indices), "#"+hashtag.Text)
+		entities = append(entities, textEntity{hashtag.Indices, shown, "https://x.com/hashtag/" + url.PathEscape(hashtag.Text), shown})
+	}
+	for _, symbol := range t.Entities.Symbols {
+		shown := cmp.Or(slice(symbol.Indices), "$"+symbol.Text)
+		entities = append(entities, textEntity{symbol.Indices, shown, "https://x.com/search?q=" + url.QueryEscape("$"+symbol.Text), shown})
+	}
+	return renderText(text, start, end, entities, t.inserts(), format)
+}
+
+func (r RichText) render(format textFormat) string {
+	text := []rune(r.Text)
+	var entities []textEntity
+	for _, entity := range r.Entities {
+		// rich text counts UTF-16 code units, JavaScript strings.
+		indices := [2]int{runeIndex(text, entity.FromIndex), runeIndex(text, entity.ToIndex)}
+		if indices[0] >= indices[1] {
+			continue
+		}
+		display := string(text[indices[0]:indices[1]])
+		entities = append(entities, textEntity{indices, display, entity.Ref.Url, display})
+	}
+	return renderText(text, 0, len(text), entities, nil, format)
+}
+
+// runeIndex converts an index of UTF-16 code units of text to an index of code points.
+func runeIndex(text []rune, utf16Index int) int {
+	units := 0
+	for i, r := range text {
+		if units >= utf16Index {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(text)
+}
+
+type textFormat int
+
+const (
+	formatPlain textFormat = iota
+	formatMarkdown
+	formatHTML
+)
+
+// textEntity is a part of text that is rendered as something else, a link to url showing display, or plain in plain
+// text. it is dropped if all are empty.
+type textEntity struct {
+	indices [2]int
+	display string
+	url     string
+	plain   string
+}
+
+// textInsert is markup put into text at a code point, style markers and inline media. nothing in plain text.
+type textInsert struct {
+	at       int
+	kind     insertKind
+	rank     int
+	seq      int
+	markdown string
+	html     string
+}
+
+// insertKind orders inserts at the same code point, styles ending there are closed before others are opened.
+type insertKind int
+
+const (
+	insertClose insertKind = iota
+	insertMedia
+	insertOpen
+)
+
+// styleInserts are the markers of text[start:end] in style, nil for styles that can't be rendered.
+func styleInserts(start, end int, style string) []textInsert {
+	var markdown, tag string
+	switch strings.ToLower(style) {
+	case "bold":
+		markdown, tag = "**", "strong"
+	case "italic":
+		markdown, tag = "_", "em"
+	case "strikethrough":
+		markdown, tag = "~~", "s"
+	default:
+		return nil
+	}
+	// ranges that nest are closed in reverse order they are opened.
+	return []textInsert{
+		{at: start, kind: insertOpen, rank: -end, markdown: markdown, html: "<" + tag + ">"},
+		{at: end, kind: insertClose, rank: -start, markdown: markdown, html: "</" + tag + ">"},
+	}
+}
+
+// renderText renders text[start:end] in format, end of zero is the whole text. entities outside of it, or overlapping
+// the ones before, are dropped. inserts inside an entity are put after it.
+func renderText(text []rune, start, end int, entities []textEntity, inserts []textInsert, format textFormat) string {
+	if end == 0 || end > len(text) {
+		end = len(text)
+	}
+	start = min(max(start, 0), end)
+	slices.SortStableFunc(entities, func(a, b textEntity) int {
+		return cmp.Compare(a.indices[0], b.indices[0])
+	})
+	if format == formatPlain {
+		inserts = nil
+	}
+	for i := range inserts {
+		inserts[i].seq = i
+	}
+	slices.SortFunc(inserts, func(a, b textInsert) int {
+		seq := cmp.Compare(a.seq, b.seq)
+		if a.kind == insertClose {
+			// bold and italic of the same text are closed in reverse too.
+			seq = -seq
+		}
+		return cmp.Or(cmp.Compare(a.at, b.at), cmp.Compare(a.kind, b.kind), cmp.Compare(a.rank, b.rank), seq)
+	})
+	var b strings.Builder
+	write := func(from, to int) {
+		for len(inserts) > 0 && inserts[0].at < to {
+			at := max(inserts[0].at, from)
+			b.WriteString(escapeText(string(text[from:at]), format))
+			b.WriteString(inserts[0].render(format))
+			from, inserts = at, inserts[1:]
+		}
+		b.WriteString(escapeText(string(text[from:to]), format))
+	}
+	cursor := start
+	for _, entity := range entities {
+		if entity.indices[0] < cursor || entity.indices[1] > end || entity.indices[0] >= entity.indices[1] {
+			continue
+		}
+		write(cursor, entity.indices[0])
+		b.WriteString(entity.render(format))
+		cursor = entity.indices[1]
+	}
+	write(cursor, end)
+	for _, insert := range inserts {
+		b.WriteString(insert.render(format))
+	}
+	// dropped media link leaves the space before it behind.
+	return strings.TrimRightFunc(b.String(), unicode.IsSpace)
+}
+
+func (i textInsert) render(format textFormat) string {
+	if format == formatHTML {
+		return i.html
+	}
+	return i.markdown
+}
+
+func (e textEntity) render(format textFormat) string {
+	switch {
+	case format == formatPlain:
+		return e.plain
+	case e.url == "" || !safeURL(e.url):
+		return escapeText(cmp.Or(e.display, e.plain), format)
+	case format == formatMarkdown:
+		return "[" + escapeText(e.display, format) + "](" + markdownURL.Replace(e.url) + ")"
+	}
+	return `<a href="` + html.EscapeString(e.url) + `" rel="noopener noreferrer nofollow">` + escapeText(e.display, format) + "</a>"
+}
+
+func safeURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http")
+}
+
+var (
+	tweetTextEscapes = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+	markdownEscapes  = strings.NewReplacer(
+		`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+		"#", `\#`, "<", `\<`, ">", `\>`, "~", `\~`, "|", `\|`, "\n", "  \n",
+	)
+	markdownURL = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20")
+)
+
+// unescapeTweetText undoes the escaping of full_text, X escapes only these three.
+func unescapeTweetText(text string) string {
+	return tweetTextEscapes.Replace(text)
+}
+
+func escapeText(text string, format textFormat) string {
+	switch format {
+	case formatMarkdown:
+		return markdownEscapes.Replace(text)
+	case formatHTML:
+		return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>\n")
+	}
+	return text
+}
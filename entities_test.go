@@ -0,0 +1,69 @@
+package helicon_test
+
+import (
+	"encoding/json"
+	"github.com/caner-cetin/helicon"
+	"testing"
+)
+
+func TestTweet_Render(t *testing.T) {
+	t.Parallel()
+	set := extractFixture(t)
+	focal := set.Tweet("1800000000000000001")
+	if got, want := focal.PlainText(), "Hey @bob, look at this 🐹 in #golang"; got != want {
+		t.Errorf("plain text\n got: %s\nwant: %s", got, want)
+	}
+	if got, want := focal.Markdown(), "Hey [@bob](https://x.com/bob), look at this 🐹 in [\\#golang](https://x.com/hashtag/golang)"; got != want {
+		t.Errorf("markdown\n got: %s\nwant: %s", got, want)
+	}
+	// reply mentions at the start are not shown.
+	if got := set.Tweet("1800000000000000002").PlainText(); got != "so cute" {
+		t.Errorf("unexpected reply text %q", got)
+	}
+
+	// no display range, media link is dropped anyway. indices are of code points of unescaped text, 💜 is one.
+	tweet := &helicon.Tweet{
+		Text: "I 💜 Go &amp; &lt;i&gt;you&lt;/i&gt; $GOOG https://t.co/abc https://t.co/media",
+		Entities: helicon.Entities{
+			Symbols: []helicon.HashtagEntity{{Text: "GOOG", Indices: [2]int{20, 25}}},
+			Urls:    []helicon.URLEntity{{Url: "https://t.co/abc", ExpandedUrl: "https://go.dev/doc/", DisplayUrl: "go.dev/doc/", Indices: [2]int{26, 42}}},
+			Media:   []helicon.MediaEntity{{URLEntity: helicon.URLEntity{Url: "https://t.co/media", Indices: [2]int{43, 61}}}},
+		},
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"plain", tweet.PlainText(), "I 💜 Go & <i>you</i> $GOOG https://go.dev/doc/"},
+		{"markdown", tweet.Markdown(), `I 💜 Go & \<i\>you\</i\> [$GOOG](https://x.com/search?q=%24GOOG) [go.dev/doc/](https://go.dev/doc/)`},
+		{"html", tweet.HTML(), `I 💜 Go &amp; &lt;i&gt;you&lt;/i&gt; <a href="https://x.com/search?q=%24GOOG" rel="noopener noreferrer nofollow">$GOOG</a> ` +
+			`<a href="https://go.dev/doc/" rel="noopener noreferrer nofollow">go.dev/doc/</a>`},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s\n got: %s\nwant: %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	unsafe := &helicon.Tweet{
+		Text:     "click https://t.co/x",
+		Entities: helicon.Entities{Urls: []helicon.URLEntity{{Url: "https://t.co/x", ExpandedUrl: `javascript:alert("x")`, DisplayUrl: "totally-safe.com", Indices: [2]int{6, 20}}}},
+	}
+	if got := unsafe.HTML(); got != "click totally-safe.com" {
+		t.Errorf("only http links must be linked, got %s", got)
+	}
+}
+
+func TestRichText_Render(t *testing.T) {
+	t.Parallel()
+	var text helicon.RichText
+	// indices of rich text are of UTF-16 code units, 😀 is two of them.
+	err := json.Unmarshal([]byte(`{"text":"😀 deleted. Learn more","entities":[{"fromIndex":12,"toIndex":22,"ref":{"type":"TimelineUrl","url":"https://help.x.com/rules-and-policies/notices-on-x"}}]}`), &text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := text.Markdown(), "😀 deleted. [Learn more](https://help.x.com/rules-and-policies/notices-on-x)"; got != want {
+		t.Errorf("markdown\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package helicon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Envelope is everything X sent for a GraphQL request, not only the part that fits into the response type. Archive
+// Raw, and re-parse it when types get better. See [WithEnvelope].
+type Envelope struct {
+	Operation string `json:"operation"`
+	// `data` of the response as is, what out of [Helicon.Execute] is decoded from.
+	Data json.RawMessage `json:"data,omitempty"`
+	// errors of the response, including the ones that came along with data.
+	Errors []GraphQLError `json:"errors,omitempty"`
+	// whole response body as X sent it, also set when X responds with an error status.
+	Raw  json.RawMessage `json:"raw,omitempty"`
+	HTTP ResponseMeta    `json:"http"`
+}
+
+// Decode decodes Data into out, for re-parsing archived envelopes.
+func (e *Envelope) Decode(out any) error {
+	if err := json.Unmarshal(e.Data, out); err != nil {
+		return fmt.Errorf("failed to decode %s data: %w", e.Operation, err)
+	}
+	return nil
+}
+
+// ResponseMeta is HTTP side of a response.
+type ResponseMeta struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	RateLimit  RateLimit   `json:"rate_limit"`
+	// x-transaction-id, what X support asks for, if you ever get to talk to one.
+	TransactionID string `json:"transaction_id,omitempty"`
+	// x-response-time, how long X says it took to respond.
+	ServerTime time.Duration `json:"server_time,omitempty"`
+	// when request was sent, and how long it took until the whole body was read.
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	// response came from [Helicon.Cache], everything else is what X sent when it was cached.
+	Cached bool `json:"cached,omitempty"`
+}
+
+// RateLimit is parsed from x-rate-limit-* headers, zero if X did not send them.
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+func newResponseMeta(resp *http.Response, startedAt time.Time) ResponseMeta {
+	meta := ResponseMeta{
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		TransactionID: resp.Header.Get("X-Transaction-Id"),
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+	meta.RateLimit.Limit, _ = strconv.Atoi(resp.Header.Get("X-Rate-Limit-Limit"))
+	meta.RateLimit.Remaining, _ = strconv.Atoi(resp.Header.Get("X-Rate-Limit-Remaining"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		meta.RateLimit.Reset = time.Unix(reset, 0)
+	}
+	if ms, err := strconv.Atoi(resp.Header.Get("X-Response-Time")); err == nil {
+		meta.ServerTime = time.Duration(ms) * time.Millisecond
+	}
+	return meta
+}
+
+// ExecuteOption configures a single [Helicon.Execute] call.
+type ExecuteOption func(*executeOptions)
+
+type executeOptions struct {
+	envelope *Envelope
+	noCache  bool
+}
+
+// WithEnvelope fills env with the raw response, GraphQL errors and HTTP metadata. env is filled even if the call fails,
+// as far as the request got.
+func WithEnvelope(env *Envelope) ExecuteOption {
+	return func(o *executeOptions) {
+		o.envelope = env
+	}
+}
+
+// WithoutCache sends the request even if [Helicon.Cache] has a fresh response, and does not cache what comes back.
+func WithoutCache() ExecuteOption {
+	return func(o *executeOptions) {
+		o.noCache = true
+	}
+}
@@ -0,0 +1,69 @@
+package helicon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewResponseMeta(t *testing.T) {
+	t.Parallel()
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("X-Rate-Limit-Limit", "150")
+	resp.Header.Set("X-Rate-Limit-Remaining", "0")
+	resp.Header.Set("X-Rate-Limit-Reset", "1760000000")
+	resp.Header.Set("X-Response-Time", "42")
+	resp.Header.Set("X-Transaction-Id", "abc")
+	startedAt := time.Now().Add(-time.Second)
+	meta := newResponseMeta(resp, startedAt)
+	if meta.StatusCode != http.StatusTooManyRequests || meta.TransactionID != "abc" || meta.ServerTime != 42*time.Millisecond {
+		t.Fatalf("unexpected meta %+v", meta)
+	}
+	if meta.RateLimit != (RateLimit{Limit: 150, Remaining: 0, Reset: time.Unix(1760000000, 0)}) {
+		t.Fatalf("unexpected rate limit %+v", meta.RateLimit)
+	}
+	if meta.Duration < time.Second {
+		t.Fatalf("duration must count from start, got %s", meta.Duration)
+	}
+	if newResponseMeta(&http.Response{Header: http.Header{}}, startedAt).RateLimit != (RateLimit{}) {
+		t.Fatal("missing headers must leave rate limit empty")
+	}
+}
+
+func TestEnvelope_Decode(t *testing.T) {
+	t.Parallel()
+	env := Envelope{Operation: "TweetDetail", Data: []byte(`{"threaded_conversation_with_injections_v2":{"instructions":[{"type":"TimelineClearCache"}]}}`)}
+	var response TweetDetailResponse
+	if err := env.Decode(&response.Data); err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.ThreadedConversationWithInjectionsV2.Instructions[0].Type != "TimelineClearCache" {
+		t.Fatalf("unexpected decode %+v", response.Data)
+	}
+}
+
+func TestHelicon_Execute_ErrorStatus(t *testing.T) {
+	body := `{"errors":[{"message":"Rate limit exceeded","code":88}]}`
+	StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		resp := StubResponse(r, http.StatusTooManyRequests, body)
+		resp.Header.Set("X-Rate-Limit-Limit", "150")
+		resp.Header.Set("X-Rate-Limit-Remaining", "0")
+		resp.Header.Set("X-Rate-Limit-Reset", "1760000000")
+		return resp, nil
+	})
+	h := stubbedHelicon()
+	var env Envelope
+	err := h.Execute(context.Background(), Operation{Name: "UserByScreenName", Variables: map[string]any{"screen_name": "jack"}}, nil, WithEnvelope(&env))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected APIError with status 429, got %v", err)
+	}
+	if string(env.Raw) != body || env.HTTP.StatusCode != http.StatusTooManyRequests || env.HTTP.RateLimit.Remaining != 0 || env.HTTP.RateLimit.Limit != 150 {
+		t.Fatalf("envelope must be filled on error status, got %+v", env)
+	}
+	if len(env.Errors) != 1 || env.Errors[0].Code != 88 || env.Data != nil {
+		t.Fatalf("unexpected errors in envelope %+v", env.Errors)
+	}
+}
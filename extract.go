@@ -0,0 +1,302 @@
+package helicon
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"strconv"
+	"strings"
+)
+
+// TweetSet is every tweet and user of a response, deduplicated, in the order they first appear.
+type TweetSet struct {
+	Tweets []*Tweet
+	Users  []*User
+	// tombstones and unavailable tweets, see [TweetResult.Err] for why they are not there.
+	Unavailable []*TweetResult
+
+	tweets map[string]*Tweet
+	users  map[string]*User
+}
+
+// Tweet returns the tweet with id, nil if it is not in the set.
+func (s *TweetSet) Tweet(id string) *Tweet {
+	return s.tweets[id]
+}
+
+// User returns the user with id, nil if it is not in the set.
+func (s *TweetSet) User(id string) *User {
+	return s.users[id]
+}
+
+// MaxNestedTweetDepth is how deep quoted and retweeted tweets are followed, a retweet of a quote tweet is two deep. X
+// doesn't send deeper ones anyway.
+const MaxNestedTweetDepth = 2
+
+// ExtractTweets walks data of any GraphQL response that has tweets or users in it, timelines, conversations, search,
+// and returns them as [Tweet] and [User]. Nothing about the layout of the response is assumed, every `tweet_results`
+// and `user_results` is picked up wherever it is. Quoted and retweeted tweets are in the set too, up to
+// [MaxNestedTweetDepth] deep. data is `data` of the response, or the whole response,
+// [Envelope.Data] works too.
+func ExtractTweets(data []byte) (*TweetSet, error) {
+	s := &TweetSet{tweets: map[string]*Tweet{}, users: map[string]*User{}}
+	dec := jsontext.NewDecoder(bytes.NewReader(data), jsonv1.DefaultOptionsV1())
+	if err := s.walk(dec); err != nil {
+		return nil, fmt.Errorf("failed to extract tweets: %w", err)
+	}
+	s.resolve()
+	return s, nil
+}
+
+// GetConversation is [Helicon.GetTweetDetails], with tweets and users of the conversation extracted, see [ExtractTweets].
+// focal tweet is s.Tweet(request.Variables.FocalTweetId).
+func (h *Helicon) GetConversation(request TweetDetailRequest, opts ...ExecuteOption) (*TweetSet, error) {
+	op, err := request.Operation()
+	if err != nil {
+		return nil, err
+	}
+	var data json.RawMessage
+	if err := h.Execute(context.Background(), op, &data, opts...); err != nil {
+		return nil, err
+	}
+	return ExtractTweets(data)
+}
+
+func (s *TweetSet) walk(dec *jsontext.Decoder) error {
+	return streamFind(dec, func(key string) bool {
+		return key == "tweet_results" || key == "user_results"
+	}, func(key string) error {
+		if key == "user_results" {
+			var results UserResults
+			if err := jsonv2.UnmarshalDecode(dec, &results); err != nil {
+				return err
+			}
+			s.addUser(results.Result)
+			return nil
+		}
+		var results TweetResults
+		if err := jsonv2.UnmarshalDecode(dec, &results); err != nil {
+			return err
+		}
+		s.addTweet(results.Result, 0)
+		return nil
+	})
+}
+
+func (s *TweetSet) addTweet(result *TweetResult, depth int) {
+	if result == nil {
+		return
+	}
+	if result.Tweet == nil {
+		s.Unavailable = append(s.Unavailable, result)
+		return
+	}
+	raw := result.Tweet
+	if raw.id() == "" {
+		return
+	}
+	s.addUser(raw.Core.UserResults.Result)
+	if raw.Card != nil {
+		for _, user := range raw.Card.Legacy.UserRefsResults {
+			s.addUser(user.Result)
+		}
+	}
+	tweet := raw.tweet()
+	existing, ok := s.tweets[tweet.ID]
+	switch {
+	case !ok:
+		s.tweets[tweet.ID] = tweet
+		s.Tweets = append(s.Tweets, tweet)
+	case existing.CreatedAt.IsZero() && !tweet.CreatedAt.IsZero():
+		*existing = *tweet
+	default:
+		// same tweet shows up more than once, focal tweet is in its own thread for example. first full one wins.
+		return
+	}
+	if depth >= MaxNestedTweetDepth {
+		return
+	}
+	for _, nested := range []*TweetResults{raw.QuotedStatusResult, raw.Legacy.RetweetedStatusResult} {
+		if nested != nil {
+			s.addTweet(nested.Result, depth+1)
+		}
+	}
+}
+
+func (s *TweetSet) addUser(raw *RawUser) {
+	if raw == nil || raw.RestId == "" {
+		// UserUnavailable and friends.
+		return
+	}
+	user := raw.user()
+	if existing, ok := s.users[user.ID]; ok {
+		if existing.ScreenName != "" || user.ScreenName == "" {
+			return
+		}
+		*existing = *user
+		return
+	}
+	s.users[user.ID] = user
+	s.Users = append(s.Users, user)
+}
+
+// resolve points tweets to their authors, the tweets and users they reply to, and mentioned users.
+func (s *TweetSet) resolve() {
+	for _, tweet := range s.Tweets {
+		tweet.Author = s.users[tweet.AuthorID]
+		tweet.InReplyToUser = s.users[tweet.InReplyToUserID]
+		if parent, ok := s.tweets[tweet.InReplyToID]; ok {
+			tweet.InReplyTo = parent
+			parent.Replies = append(parent.Replies, tweet)
+		}
+		tweet.QuotedTweet = s.tweets[tweet.QuotedTweetID]
+		tweet.RetweetedTweet = s.tweets[tweet.RetweetedTweetID]
+		for i, mention := range tweet.Mentions {
+			if user, ok := s.users[mention.ID]; ok {
+				tweet.Mentions[i] = user
+			}
+		}
+	}
+}
+
+func (raw *RawTweet) id() string {
+	return cmp.Or(raw.Legacy.IdStr, raw.RestId)
+}
+
+// nestedID is ID of the tweet in results, empty if there is none.
+func nestedID(results *TweetResults) string {
+	if results == nil || results.Result == nil || results.Result.Tweet == nil {
+		return ""
+	}
+	return results.Result.Tweet.id()
+}
+
+func (raw *RawTweet) tweet() *Tweet {
+	legacy := raw.Legacy
+	tweet := &Tweet{
+		ID:                raw.id(),
+		ConversationID:    legacy.ConversationIdStr,
+		AuthorID:          legacy.UserIdStr,
+		CreatedAt:         legacy.CreatedAt.Time,
+		Text:              legacy.FullText,
+		Entities:          legacy.Entities,
+		Lang:              legacy.Lang,
+		Source:            sourceName(raw.Source),
+		InReplyToID:       legacy.InReplyToStatusIdStr,
+		InReplyToUserID:   legacy.InReplyToUserIdStr,
+		QuotedTweetID:     cmp.Or(nestedID(raw.QuotedStatusResult), legacy.QuotedStatusIdStr),
+		RetweetedTweetID:  nestedID(legacy.RetweetedStatusResult),
+		ReplyCount:        legacy.ReplyCount,
+		RetweetCount:      legacy.RetweetCount,
+		QuoteCount:        legacy.QuoteCount,
+		LikeCount:         legacy.FavoriteCount,
+		BookmarkCount:     legacy.BookmarkCount,
+		ViewCount:         int(raw.Views.Count),
+		ViewsEnabled:      raw.Views.State == "EnabledWithCount",
+		Liked:             legacy.Favorited,
+		Retweeted:         legacy.Retweeted,
+		Bookmarked:        legacy.Bookmarked,
+		PossiblySensitive: legacy.PossiblySensitive,
+		EditIDs:           raw.EditControl.EditTweetIds,
+		EditableUntil:     raw.EditControl.EditableUntilMsecs.Time,
+		HasCommunityNotes: raw.HasBirdwatchNotes,
+	}
+	if tweet.AuthorID == "" && raw.Core.UserResults.Result != nil {
+		tweet.AuthorID = raw.Core.UserResults.Result.RestId
+	}
+	if len(legacy.DisplayTextRange) == 2 {
+		tweet.DisplayTextRange = [2]int{legacy.DisplayTextRange[0], legacy.DisplayTextRange[1]}
+	}
+	if raw.NoteTweet != nil && raw.NoteTweet.NoteTweetResults.Result != nil {
+		note := raw.NoteTweet.NoteTweetResults.Result
+		// note text has no reply mentions nor media link, all of it is shown.
+		tweet.Note = true
+		tweet.Text, tweet.Entities, tweet.DisplayTextRange = note.Text, note.EntitySet, [2]int{}
+		tweet.RichTextTags, tweet.InlineMedia = note.Richtext.RichtextTags, note.Media.InlineMedia
+	}
+	for _, mention := range tweet.Entities.UserMentions {
+		tweet.Mentions = append(tweet.Mentions, &User{ID: mention.IdStr, ScreenName: mention.ScreenName, Name: mention.Name})
+	}
+	if legacy.ExtendedEntities != nil {
+		for _, media := range legacy.ExtendedEntities.Media {
+			tweet.Media = append(tweet.Media, media.media())
+		}
+	}
+	if raw.Article != nil && raw.Article.ArticleResults.Result != nil {
+		tweet.Article = raw.Article.ArticleResults.Result.article()
+	}
+	if raw.BirdwatchPivot != nil {
+		tweet.CommunityNote = raw.BirdwatchPivot.summary()
+	}
+	if raw.Card != nil {
+		tweet.Card = raw.Card.card()
+	}
+	if place := legacy.Place; place != nil && place.Id != "" {
+		tweet.Place = &Place{
+			ID:          place.Id,
+			Name:        place.Name,
+			FullName:    place.FullName,
+			Type:        place.PlaceType,
+			Country:     place.Country,
+			CountryCode: place.CountryCode,
+		}
+		if place.BoundingBox != nil && len(place.BoundingBox.Coordinates) > 0 {
+			tweet.Place.BoundingBox = place.BoundingBox.Coordinates[0]
+		}
+	}
+	return tweet
+}
+
+func (raw *RawUser) user() *User {
+	legacy := raw.Legacy
+	user := &User{
+		ID:               raw.RestId,
+		ScreenName:       cmp.Or(raw.Core.ScreenName, legacy.ScreenName),
+		Name:             cmp.Or(raw.Core.Name, legacy.Name),
+		Description:      legacy.Description,
+		Location:         cmp.Or(raw.Location.Location, legacy.Location),
+		URL:              legacy.Url,
+		ProfileImageURL:  cmp.Or(raw.Avatar.ImageUrl, legacy.ProfileImageUrlHttps),
+		ProfileBannerURL: legacy.ProfileBannerUrl,
+		FollowersCount:   legacy.FollowersCount,
+		FollowingCount:   legacy.FriendsCount,
+		TweetCount:       legacy.StatusesCount,
+		LikeCount:        legacy.FavouritesCount,
+		ListedCount:      legacy.ListedCount,
+		MediaCount:       legacy.MediaCount,
+		Verified:         raw.Verification.Verified || legacy.Verified,
+		BlueVerified:     raw.IsBlueVerified,
+		Protected:        raw.Privacy.Protected || legacy.Protected,
+		PinnedTweetIDs:   legacy.PinnedTweetIdsStr,
+	}
+	if urls := legacy.Entities.Url.Urls; len(urls) > 0 && urls[0].ExpandedUrl != "" {
+		user.URL = urls[0].ExpandedUrl
+	}
+	if user.CreatedAt = raw.Core.CreatedAt.Time; user.CreatedAt.IsZero() {
+		user.CreatedAt = legacy.CreatedAt.Time
+	}
+	return user
+}
+
+// parseCount parses counts X sends as strings, zero if it is empty.
+func parseCount(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// sourceName is the link text of source, `<a href="..." rel="nofollow">Twitter Web App</a>`.
+func sourceName(source string) string {
+	if start := strings.IndexByte(source, '>'); start >= 0 {
+		if end := strings.LastIndex(source, "</a>"); end > start {
+			return source[start+1 : end]
+		}
+	}
+	return source
+}
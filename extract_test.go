@@ -0,0 +1,124 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"os"
+	"testing"
+	"time"
+)
+
+func extractFixture(t *testing.T) *helicon.TweetSet {
+	t.Helper()
+	data, err := os.ReadFile("testdata/tweet_detail.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := helicon.ExtractTweets(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestExtractTweets(t *testing.T) {
+	t.Parallel()
+	set := extractFixture(t)
+	if len(set.Tweets) != 3 || len(set.Users) != 2 {
+		t.Fatalf("expected 3 tweets and 2 users, got %d and %d", len(set.Tweets), len(set.Users))
+	}
+
+	focal := set.Tweet("1800000000000000001")
+	if focal == nil || set.Tweets[0] != focal {
+		t.Fatal("focal tweet must come first")
+	}
+	if !focal.CreatedAt.Equal(time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected created at %v", focal.CreatedAt)
+	}
+	if focal.ViewCount != 48213 || !focal.ViewsEnabled || focal.LikeCount != 321 || focal.RetweetCount != 17 || !focal.Liked {
+		t.Errorf("unexpected counts %+v", focal)
+	}
+	if focal.Source != "Twitter Web App" {
+		t.Errorf("unexpected source %q", focal.Source)
+	}
+	if !focal.EditableUntil.Equal(time.UnixMilli(1717243200000)) {
+		t.Errorf("unexpected editable until %v", focal.EditableUntil)
+	}
+	if len(focal.Media) != 2 || focal.Media[0].Type != helicon.MediaPhoto || focal.Media[0].AltText != "a gopher on a bridge" || focal.Media[0].Width != 1920 {
+		t.Errorf("unexpected media %+v", focal.Media)
+	}
+	if focal.Place == nil || focal.Place.CountryCode != "TR" || len(focal.Place.BoundingBox) != 4 {
+		t.Errorf("unexpected place %+v", focal.Place)
+	}
+
+	alice, bob := set.User("100"), set.User("200")
+	if alice == nil || bob == nil {
+		t.Fatal("authors must be extracted")
+	}
+	// second appearance of alice is partial, it must not replace the first.
+	if alice.ScreenName != "alice" || alice.URL != "https://alice.dev" || alice.FollowingCount != 310 || !alice.BlueVerified {
+		t.Errorf("unexpected user %+v", alice)
+	}
+	if !alice.CreatedAt.Equal(time.Date(2006, 3, 21, 20, 50, 14, 0, time.UTC)) {
+		t.Errorf("unexpected user created at %v", alice.CreatedAt)
+	}
+	// legacy layout.
+	if bob.ScreenName != "bob" || bob.ProfileImageURL == "" || bob.TweetCount != 77 {
+		t.Errorf("unexpected user %+v", bob)
+	}
+
+	reply, answer := set.Tweet("1800000000000000002"), set.Tweet("1800000000000000003")
+	if focal.Author != alice || reply.Author != bob || answer.Author != alice {
+		t.Error("authors must be resolved to users of the set")
+	}
+	if reply.InReplyTo != focal || reply.InReplyToUser != alice || answer.InReplyTo != reply {
+		t.Error("replies must be resolved to tweets of the set")
+	}
+	if len(focal.Replies) != 1 || focal.Replies[0] != reply || len(reply.Replies) != 1 || reply.Replies[0] != answer {
+		t.Error("replies must be linked back to their parents")
+	}
+	if len(focal.Mentions) != 1 || focal.Mentions[0] != bob {
+		t.Error("mentions must be resolved to users of the set")
+	}
+	if reply.ViewCount != 0 || reply.ViewsEnabled {
+		t.Error("views without count must be zero")
+	}
+}
+
+func TestExtractTweets_Nested(t *testing.T) {
+	t.Parallel()
+	// retweet of a quote tweet of a quote tweet, the last one is too deep. alice and her photo are in every one.
+	set, err := helicon.ExtractTweets([]byte(`{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1",
+		"core":{"user_results":{"result":{"rest_id":"200","legacy":{"screen_name":"bob"}}}},
+		"legacy":{"id_str":"1","user_id_str":"200","full_text":"RT @alice: look",
+			"extended_entities":{"media":[{"id_str":"9","media_key":"3_9","type":"photo"}]},
+			"retweeted_status_result":{"result":{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"2",
+				"core":{"user_results":{"result":{"rest_id":"100","legacy":{"screen_name":"alice"}}}},
+				"legacy":{"id_str":"2","user_id_str":"100","full_text":"look","is_quote_status":true,"quoted_status_id_str":"3",
+					"extended_entities":{"media":[{"id_str":"9","media_key":"3_9","type":"photo"}]}},
+				"quoted_status_result":{"result":{"__typename":"Tweet","rest_id":"3",
+					"core":{"user_results":{"result":{"rest_id":"100","legacy":{"screen_name":"alice"}}}},
+					"legacy":{"id_str":"3","user_id_str":"100","full_text":"older","quoted_status_id_str":"4"},
+					"quoted_status_result":{"result":{"__typename":"Tweet","rest_id":"4","legacy":{"id_str":"4","full_text":"oldest"}}}}}}}}}}},
+		"other":{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"5","legacy":{"id_str":"5","full_text":"quoting a deleted one","quoted_status_id_str":"6"},
+			"quoted_status_result":{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post was deleted by the Post author."}}}}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Tweets) != 4 || len(set.Users) != 2 || len(set.Media()) != 1 || len(set.Unavailable) != 1 {
+		t.Fatalf("expected 4 tweets, 2 users, 1 media and 1 unavailable, got %d, %d, %d and %d",
+			len(set.Tweets), len(set.Users), len(set.Media()), len(set.Unavailable))
+	}
+	retweet, original, quoted := set.Tweet("1"), set.Tweet("2"), set.Tweet("3")
+	if retweet.RetweetedTweetID != "2" || retweet.RetweetedTweet != original || original.Author != set.User("100") {
+		t.Errorf("retweeted tweet must be resolved, got %+v", retweet.RetweetedTweet)
+	}
+	if original.QuotedTweetID != "3" || original.QuotedTweet != quoted || quoted.Author != original.Author {
+		t.Errorf("quoted tweet must be resolved, got %+v", original.QuotedTweet)
+	}
+	if quoted.QuotedTweetID != "4" || quoted.QuotedTweet != nil || set.Tweet("4") != nil {
+		t.Errorf("tweets deeper than %d must be left out", helicon.MaxNestedTweetDepth)
+	}
+	if deleted := set.Tweet("5"); deleted.QuotedTweetID != "6" || deleted.QuotedTweet != nil {
+		t.Errorf("unavailable quoted tweet must only have its ID, got %+v", deleted.QuotedTweet)
+	}
+}
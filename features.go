@@ -0,0 +1,109 @@
+package helicon
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// FeatureSet is the `features` parameter of GraphQL requests, feature switch name => enabled.
+//
+// X rejects requests with "The following features cannot be null" whenever a switch the operation expects is missing,
+// and adds new switches all the time, so unlike [TweetDetailFeatures] this does not need a release to keep up.
+// See [QueryRegistry.OperationFeatures] for the switches current web client sends.
+type FeatureSet map[string]bool
+
+// FeatureSetFrom converts a features struct with json tags, like [TweetDetailFeatures], into a [FeatureSet].
+// A FeatureSet or map[string]bool is copied as is.
+func FeatureSetFrom(features any) (FeatureSet, error) {
+	switch f := features.(type) {
+	case nil:
+		return FeatureSet{}, nil
+	case FeatureSet:
+		return maps.Clone(f), nil
+	case map[string]bool:
+		return maps.Clone(FeatureSet(f)), nil
+	}
+	raw, err := json.Marshal(features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal features: %w", err)
+	}
+	var set FeatureSet
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("features must be an object of booleans: %w", err)
+	}
+	return set, nil
+}
+
+// Merge returns a new set with every set applied on top of f in order, later sets override earlier ones.
+func (f FeatureSet) Merge(overrides ...FeatureSet) FeatureSet {
+	merged := maps.Clone(f)
+	if merged == nil {
+		merged = FeatureSet{}
+	}
+	for _, override := range overrides {
+		maps.Copy(merged, override)
+	}
+	return merged
+}
+
+// With returns a new set with feature set to enabled.
+func (f FeatureSet) With(feature string, enabled bool) FeatureSet {
+	return f.Merge(FeatureSet{feature: enabled})
+}
+
+// Without returns a new set without features.
+func (f FeatureSet) Without(features ...string) FeatureSet {
+	trimmed := maps.Clone(f)
+	for _, feature := range features {
+		delete(trimmed, feature)
+	}
+	return trimmed
+}
+
+// splitQuotedList splits `"a","b"` into [a b].
+func splitQuotedList(list string) []string {
+	var items []string
+	for item := range strings.SplitSeq(list, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseInitialStateFeatures returns feature switch config embedded into web client html as `window.__INITIAL_STATE__`.
+// Defaults come from `featureSwitch.defaultConfig` and are overridden by `featureSwitch.user.config`. Only boolean
+// switches are returned, rest are experiment knobs that GraphQL does not care about.
+func ParseInitialStateFeatures(html string) (FeatureSet, error) {
+	_, state, found := strings.Cut(html, "window.__INITIAL_STATE__=")
+	if !found {
+		return nil, fmt.Errorf("no __INITIAL_STATE__ in page")
+	}
+	type switchConfig map[string]struct {
+		Value any `json:"value"`
+	}
+	var initialState struct {
+		FeatureSwitch struct {
+			DefaultConfig switchConfig `json:"defaultConfig"`
+			User          struct {
+				Config switchConfig `json:"config"`
+			} `json:"user"`
+		} `json:"featureSwitch"`
+	}
+	// decoder stops after the first value, whatever javascript follows the object is not read.
+	if err := json.NewDecoder(strings.NewReader(state)).Decode(&initialState); err != nil {
+		return nil, fmt.Errorf("failed to decode __INITIAL_STATE__: %w", err)
+	}
+	features := FeatureSet{}
+	for _, config := range []switchConfig{initialState.FeatureSwitch.DefaultConfig, initialState.FeatureSwitch.User.Config} {
+		for name, setting := range config {
+			if enabled, ok := setting.Value.(bool); ok {
+				features[name] = enabled
+			}
+		}
+	}
+	return features, nil
+}
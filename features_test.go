@@ -0,0 +1,43 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"testing"
+)
+
+func TestFeatureSet(t *testing.T) {
+	t.Parallel()
+	fromStruct, err := helicon.FeatureSetFrom(helicon.NewTweetDetailFeatures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fromStruct) != 31 || !fromStruct["rweb_video_screen_enabled"] {
+		t.Fatalf("unexpected features from struct: %v", fromStruct)
+	}
+	discovered := helicon.FeatureSet{"rweb_video_screen_enabled": false, "brand_new_switch": false}
+	merged := discovered.Merge(fromStruct, helicon.FeatureSet{"brand_new_switch": true})
+	if !merged["rweb_video_screen_enabled"] || !merged["brand_new_switch"] || len(merged) != 32 {
+		t.Fatalf("unexpected merge result: %v", merged)
+	}
+	if discovered["brand_new_switch"] {
+		t.Fatal("merge must not modify the receiver")
+	}
+	if without := merged.With("x", true).Without("x", "brand_new_switch"); len(without) != 31 {
+		t.Fatalf("unexpected set after With/Without: %v", without)
+	}
+}
+
+func TestParseInitialStateFeatures(t *testing.T) {
+	t.Parallel()
+	html := `<script nonce="x">window.__INITIAL_STATE__={"featureSwitch":{"defaultConfig":{"rweb_video_screen_enabled":{"value":false},"grok_settings_age_restriction_enabled":{"value":true},"tweet_limit":{"value":25}},"user":{"config":{"rweb_video_screen_enabled":{"value":true}}}}};window.__META_DATA__={"env":"prod"};</script>`
+	features, err := helicon.ParseInitialStateFeatures(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 2 || !features["rweb_video_screen_enabled"] || !features["grok_settings_age_restriction_enabled"] {
+		t.Fatalf("unexpected features %v", features)
+	}
+	if _, err := helicon.ParseInitialStateFeatures("<html></html>"); err == nil {
+		t.Fatal("expected error without __INITIAL_STATE__")
+	}
+}
@@ -0,0 +1,134 @@
+package helicon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// HealEvent reports what the GraphQL layer changed to get a failed request through, see [Helicon.OnHeal].
+type HealEvent struct {
+	Operation string
+	// features X said are missing, with the value they were added with ([Helicon.MissingFeatureDefault]).
+	// you probably want these in your defaults.
+	AddedFeatures FeatureSet
+	// set when query ID was rotated, StaleQueryID is the one X rejected, QueryID is the one retry used.
+	StaleQueryID string
+	QueryID      string
+	// error of the original request.
+	Cause error
+	// error of the retry, nil if healing worked.
+	Err error
+}
+
+// graphQLBuilder builds the request for a query ID and the feature switches registry knows for the operation.
+// it is called again for the retry, so it must return a new request every time.
+type graphQLBuilder func(queryID string, discovered FeatureSet) (*http.Request, error)
+
+// graphQLSend runs a GraphQL request, and if X rejects it for missing features or a stale query ID, fixes the request
+// and retries it exactly once. fixes stick, missing features are learned by [QueryRegistry] and query ID is refreshed.
+//
+// send hands out the response, whole or the stream of it. whatever send returned last is returned along with its error.
+func graphQLSend[T any](h *Helicon, operation string, fallbackQueryID string, build graphQLBuilder, send func(*http.Request) (T, error)) (T, error) {
+	var zero T
+	registry := h.queryRegistry()
+	queryID := h.QueryID(operation, fallbackQueryID)
+	if queryID == "" {
+		return zero, fmt.Errorf("query ID of %s is unknown, and could not be discovered", operation)
+	}
+	req, err := build(queryID, registry.OperationFeatures(operation))
+	if err != nil {
+		return zero, err
+	}
+	response, err := send(req)
+	if err == nil {
+		return response, nil
+	}
+	event, healed := h.heal(operation, queryID, err)
+	if !healed {
+		return response, err
+	}
+	if req, event.Err = build(event.QueryID, registry.OperationFeatures(operation)); event.Err == nil {
+		response, event.Err = send(req)
+	}
+	if h.OnHeal != nil {
+		h.OnHeal(*event)
+	}
+	return response, event.Err
+}
+
+// heal inspects the error of a failed request and fixes what it can. returns false if there is nothing to fix.
+func (h *Helicon) heal(operation string, queryID string, cause error) (*HealEvent, bool) {
+	var apiErr *APIError
+	if !errors.As(cause, &apiErr) {
+		return nil, false
+	}
+	event := HealEvent{Operation: operation, QueryID: queryID, Cause: cause}
+	registry := h.queryRegistry()
+	if missing := missingFeatures(apiErr.Body); len(missing) > 0 {
+		event.AddedFeatures = FeatureSet{}
+		for _, feature := range missing {
+			event.AddedFeatures[feature] = h.MissingFeatureDefault
+		}
+		registry.LearnFeatures(operation, event.AddedFeatures)
+		slog.Warn("X requires features that are not sent, retrying with them added", "operation", operation, "features", missing)
+		return &event, true
+	}
+	if staleQueryID(apiErr) {
+		if err := registry.Refresh(h); err != nil {
+			slog.Warn("failed to refresh GraphQL query IDs", "operation", operation, "error", err)
+			return nil, false
+		}
+		refreshed, ok := registry.QueryID(operation)
+		if !ok || refreshed == queryID {
+			return nil, false
+		}
+		event.StaleQueryID, event.QueryID = queryID, refreshed
+		slog.Warn("query ID is rotated, retrying with the new one", "operation", operation, "stale", queryID, "current", refreshed)
+		return &event, true
+	}
+	return nil, false
+}
+
+// missingFeatures parses "The following features cannot be null: a, b" errors.
+func missingFeatures(body []byte) []string {
+	var response graphQLResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil
+	}
+	var missing []string
+	for _, e := range response.Errors {
+		_, list, found := strings.Cut(e.Message, "features cannot be null:")
+		if !found {
+			continue
+		}
+		for feature := range strings.SplitSeq(list, ",") {
+			if feature = strings.TrimSpace(feature); feature != "" && !slices.Contains(missing, feature) {
+				missing = append(missing, feature)
+			}
+		}
+	}
+	return missing
+}
+
+// staleQueryID is true for errors X returns when query ID does not exist anymore, a bare 404 or a "query not found" error.
+func staleQueryID(apiErr *APIError) bool {
+	if apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	var response graphQLResponse
+	if err := json.Unmarshal(apiErr.Body, &response); err != nil {
+		return false
+	}
+	for _, e := range response.Errors {
+		message := strings.ToLower(e.Message)
+		if strings.Contains(message, "query not found") || strings.Contains(message, "query: unspecified") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package helicon
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestHealMissingFeatures(t *testing.T) {
+	t.Parallel()
+	h := Helicon{QueryRegistry: &QueryRegistry{}, MissingFeatureDefault: true}
+	cause := &APIError{StatusCode: 400, Body: []byte(`{"errors":[{"message":"The following features cannot be null: responsive_web_grok_imagine_annotation_enabled, payments_enabled","extensions":{"name":"BadRequestError"}}]}`)}
+	event, healed := h.heal("TweetDetail", QueryId, cause)
+	if !healed {
+		t.Fatal("expected missing features to be healed")
+	}
+	if !event.AddedFeatures["payments_enabled"] || !event.AddedFeatures["responsive_web_grok_imagine_annotation_enabled"] || len(event.AddedFeatures) != 2 {
+		t.Fatalf("unexpected added features %v", event.AddedFeatures)
+	}
+	if event.QueryID != QueryId || event.StaleQueryID != "" {
+		t.Fatalf("query ID must not change when features are missing: %+v", event)
+	}
+	learned := h.QueryRegistry.OperationFeatures("TweetDetail")
+	if !learned["payments_enabled"] {
+		t.Fatalf("features are not learned by registry: %v", learned)
+	}
+	if _, healed := h.heal("TweetDetail", QueryId, &APIError{StatusCode: 429, Body: []byte(`{"errors":[{"message":"Rate limit exceeded"}]}`)}); healed {
+		t.Fatal("rate limits can't be healed")
+	}
+}
+
+func TestStaleQueryID(t *testing.T) {
+	t.Parallel()
+	if !staleQueryID(&APIError{StatusCode: 404}) {
+		t.Error("bare 404 is a stale query ID")
+	}
+	if !staleQueryID(&APIError{StatusCode: 400, Body: []byte(`{"errors":[{"message":"Query not found"}]}`)}) {
+		t.Error("query not found is a stale query ID")
+	}
+	if staleQueryID(&APIError{StatusCode: 400, Body: []byte(`{"errors":[{"message":"The following features cannot be null: a"}]}`)}) {
+		t.Error("missing features is not a stale query ID")
+	}
+	if got := missingFeatures([]byte(`{"errors":[{"message":"The following features cannot be null: a, b"},{"message":"The following features cannot be null: b"}]}`)); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("unexpected missing features %v", got)
+	}
+}
+
+func TestHealMissingFeatures_Refresh(t *testing.T) {
+	StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		switch r.URL.Host + r.URL.Path {
+		case "x.com/i/flow/login/":
+			// X's config starts sending the switch after it was healed.
+			return StubResponse(r, http.StatusOK, `<script src="https://abs.twimg.com/responsive-web/client-web/main.cafe1234.js"></script>`+
+				`<script>window.__INITIAL_STATE__={"featureSwitch":{"defaultConfig":{"payments_enabled":{"value":true}}}};</script>`), nil
+		case "abs.twimg.com/responsive-web/client-web/main.cafe1234.js":
+			return StubResponse(r, http.StatusOK, `e.exports={queryId:"detail",operationName:"TweetDetail",operationType:"query",metadata:{featureSwitches:["payments_enabled"],fieldToggles:[]}}`), nil
+		}
+		return StubResponse(r, http.StatusNotFound, ""), nil
+	})
+	h := Helicon{QueryRegistry: &QueryRegistry{}, BootstrapCache: &BootstrapCache{Disabled: true}}
+	cause := &APIError{StatusCode: 400, Body: []byte(`{"errors":[{"message":"The following features cannot be null: payments_enabled, grok_enabled"}]}`)}
+	if _, healed := h.heal("TweetDetail", QueryId, cause); !healed {
+		t.Fatal("expected missing features to be healed")
+	}
+	if features := h.QueryRegistry.OperationFeatures("TweetDetail"); features["payments_enabled"] || features["grok_enabled"] || len(features) != 2 {
+		t.Fatalf("unexpected healed features %v", features)
+	}
+	if err := h.QueryRegistry.Refresh(&h); err != nil {
+		t.Fatal(err)
+	}
+	features := h.QueryRegistry.OperationFeatures("TweetDetail")
+	if !features["payments_enabled"] {
+		t.Errorf("learned switch must not override the config, got %v", features)
+	}
+	if enabled, ok := features["grok_enabled"]; !ok || enabled {
+		t.Errorf("switch config does not know must stay learned, got %v", features)
+	}
+}
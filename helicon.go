@@ -11,6 +11,27 @@ type Helicon struct {
 	Credentials TwitterCredentials
 	UserAgent   string
 	Cookies     TwitterCookies
+	// numeric ID of the logged-in account, taken from the `twid` cookie (u%3D<id>) at login.
+	// empty if tokens were loaded from keyring, as keyring only stores the tokens.
+	UserID string
+	// how many times [Helicon.Login] runs the flow when X finishes it without handing out cookies.
+	// zero means [DefaultMaxLoginAttempts].
+	MaxLoginAttempts int
+	// where tokens are saved and loaded from, OS keyring ([KeyringStore]) if nil.
+	TokenStore TokenStore
+	// on-disk cache of main.js discovery, see [Helicon.Bootstrap]. defaults are used if nil.
+	BootstrapCache *BootstrapCache
+	// GraphQL query IDs, [DefaultQueryRegistry] if nil.
+	QueryRegistry *QueryRegistry
+	// called whenever a GraphQL request is retried after adding missing features or refreshing a rotated query ID.
+	OnHeal func(HealEvent)
+	// value of features X says are missing when they are added by self-healing, see [HealEvent].
+	MissingFeatureDefault bool
+	// when set, [Helicon.Execute] fails with [DriftError] instead of decoding a response that has fields its Go type has
+	// no place for, or has them with a different type. missing and untyped fields are not errors, see [DetectDrift].
+	StrictDecoding bool
+	// responses of queries are cached here if set, see [ResponseCache]. nil means every call is a request.
+	Cache *ResponseCache
 }
 type TwitterCredentials struct {
 	Username string
@@ -0,0 +1,42 @@
+package helicon
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helpers shared by tests of both packages, exported ones are used by helicon_test too.
+
+// RoundTripFunc stands in for transports of http clients, see StubTransport.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// StubTransport answers every request of http.DefaultClient, and of clients without their own transport, with fn until
+// the test ends. tests using it must not be parallel.
+func StubTransport(t *testing.T, fn RoundTripFunc) {
+	t.Helper()
+	transport, clientTransport := http.DefaultTransport, http.DefaultClient.Transport
+	http.DefaultTransport, http.DefaultClient.Transport = fn, fn
+	t.Cleanup(func() {
+		http.DefaultTransport, http.DefaultClient.Transport = transport, clientTransport
+	})
+}
+
+// StubResponse is a response to r with status and body.
+func StubResponse(r *http.Request, status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: r}
+}
+
+// stubbedHelicon is a client that knows query ID of UserByScreenName and does not try to discover others, for
+// sending requests to StubTransport.
+func stubbedHelicon() *Helicon {
+	h := &Helicon{QueryRegistry: &QueryRegistry{refreshedAt: time.Now()}}
+	h.QueryRegistry.Set(OperationInfo{QueryID: "user", OperationName: "UserByScreenName", OperationType: string(OperationQuery)})
+	return h
+}
@@ -0,0 +1,110 @@
+package helicon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// TwitterTime is created_at of tweets and users, in Ruby date format, "Sat Jun 01 11:00:00 +0000 2024". empty is zero
+// time. it is marshalled back the same way.
+//
+// a value that fails to parse is logged and left zero, one bad field must not lose the whole tweet.
+type TwitterTime struct {
+	time.Time
+}
+
+func (t *TwitterTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err == nil {
+		if t.Time, err = parseTwitterTime(value); err == nil {
+			return nil
+		}
+	}
+	slog.Warn("failed to decode twitter time", "value", string(data), "error", err)
+	t.Time = time.Time{}
+	return nil
+}
+
+func (t TwitterTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(t.UTC().Format(time.RubyDate))
+}
+
+// UnixMilliTime is a time X sends as unix milliseconds in a string, editable_until_msecs "1717243200000". empty is
+// zero time.
+type UnixMilliTime struct {
+	time.Time
+}
+
+func (t *UnixMilliTime) UnmarshalJSON(data []byte) error {
+	var ms Int64String
+	if err := ms.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if ms != 0 {
+		t.Time = time.UnixMilli(int64(ms))
+	}
+	return nil
+}
+
+func (t UnixMilliTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte(`""`), nil
+	}
+	return Int64String(t.UnixMilli()).MarshalJSON()
+}
+
+// Int64String is a number X sends as a string, view count "48213" for example. empty is zero, plain numbers are read
+// too. it is marshalled as a string.
+//
+// a value that fails to parse is logged and left zero, like [TwitterTime].
+type Int64String int64
+
+func (n *Int64String) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	*n = 0
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			slog.Warn("failed to decode numeric string", "value", string(data), "error", err)
+			return nil
+		}
+	}
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		slog.Warn("failed to parse numeric string", "value", value, "error", err)
+		return nil
+	}
+	*n = Int64String(parsed)
+	return nil
+}
+
+func (n Int64String) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(n), 10))
+}
+
+// parseTwitterTime parses created_at of tweets and users, zero time if it is empty.
+func parseTwitterTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RubyDate, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q: %w", value, err)
+	}
+	return t.UTC(), nil
+}
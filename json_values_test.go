@@ -0,0 +1,67 @@
+package helicon_test
+
+import (
+	"encoding/json"
+	"github.com/caner-cetin/helicon"
+	"testing"
+	"time"
+)
+
+func TestJSONValues(t *testing.T) {
+	t.Parallel()
+	var values struct {
+		CreatedAt     helicon.TwitterTime   `json:"created_at"`
+		EditableUntil helicon.UnixMilliTime `json:"editable_until_msecs"`
+		Views         helicon.Int64String   `json:"views"`
+		Plain         helicon.Int64String   `json:"plain"`
+		Empty         helicon.Int64String   `json:"empty"`
+	}
+	data := `{"created_at":"Sat Jun 01 11:00:00 +0000 2024","editable_until_msecs":"1717243200000","views":"48213","plain":7,"empty":""}`
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		t.Fatal(err)
+	}
+	if !values.CreatedAt.Equal(time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC)) || !values.EditableUntil.Equal(time.UnixMilli(1717243200000)) {
+		t.Errorf("unexpected times %v, %v", values.CreatedAt, values.EditableUntil)
+	}
+	if values.Views != 48213 || values.Plain != 7 || values.Empty != 0 {
+		t.Errorf("unexpected numbers %d, %d, %d", values.Views, values.Plain, values.Empty)
+	}
+	marshalled, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"created_at":"Sat Jun 01 11:00:00 +0000 2024","editable_until_msecs":"1717243200000","views":"48213","plain":"7","empty":"0"}`; string(marshalled) != want {
+		t.Errorf("unexpected JSON\n got: %s\nwant: %s", marshalled, want)
+	}
+
+	// values that fail to parse are left zero, not failed.
+	for _, invalid := range []string{`{"created_at":"2024-06-01T11:00:00Z","views":"many"}`, `{"created_at":1717239600,"views":{"count":1}}`} {
+		if err := json.Unmarshal([]byte(invalid), &values); err != nil {
+			t.Errorf("%s must decode, got %v", invalid, err)
+		}
+		if !values.CreatedAt.IsZero() || values.Views != 0 {
+			t.Errorf("%s must decode to zero values, got %v, %d", invalid, values.CreatedAt, values.Views)
+		}
+	}
+}
+
+func TestExtractTweets_MalformedValues(t *testing.T) {
+	t.Parallel()
+	set, err := helicon.ExtractTweets([]byte(`{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1",
+		"core":{"user_results":{"result":{"__typename":"User","rest_id":"2","core":{"screen_name":"jack","created_at":"yesterday"}}}},
+		"views":{"count":"48k","state":"EnabledWithCount"},
+		"legacy":{"id_str":"1","user_id_str":"2","full_text":"hello","favorite_count":3,"created_at":"2024-06-01T11:00:00Z"}}}}`))
+	if err != nil {
+		t.Fatalf("a value that does not parse must not fail the tweets, got %v", err)
+	}
+	tweet := set.Tweet("1")
+	if tweet == nil || tweet.Text != "hello" || tweet.LikeCount != 3 {
+		t.Fatalf("expected tweet with its fields, got %+v", tweet)
+	}
+	if !tweet.CreatedAt.IsZero() || tweet.ViewCount != 0 {
+		t.Errorf("expected unparsable values to be zero, got %v, %d", tweet.CreatedAt, tweet.ViewCount)
+	}
+	if user := set.User("2"); user == nil || user.ScreenName != "jack" || !user.CreatedAt.IsZero() {
+		t.Errorf("expected author with zero created_at, got %+v", user)
+	}
+}
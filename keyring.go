@@ -2,12 +2,115 @@ package helicon
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/zalando/go-keyring"
+	"log/slog"
+	"slices"
 	"strings"
 )
 
-// SaveTokensToKeyring under service "helicon",
+// TokenStore is where [Helicon.SaveTokensToKeyring] and [Helicon.LoadTokensFromKeyring] keep the tokens,
+// one entry per username. [KeyringStore] is used if [Helicon.TokenStore] is nil.
+type TokenStore interface {
+	Set(username string, secret string) error
+	// Get returns [keyring.ErrNotFound] if there is nothing for username.
+	Get(username string) (string, error)
+	// Delete returns nil if there is nothing for username.
+	Delete(username string) error
+	// List returns every username that has tokens in the store.
+	List() ([]string, error)
+}
+
+// KeyringStore stores tokens in OS keyring under service "helicon".
+//
+// keyring has no way of listing entries, so usernames are also kept in an index entry, see [KeyringStore.List].
+type KeyringStore struct{}
+
+// keyringProfilesUser is the index entry, username can't contain \x1F so it wont collide with a real profile.
+const keyringProfilesUser = "\x1Fprofiles"
+
+func (KeyringStore) Set(username string, secret string) error {
+	if err := keyring.Set("helicon", username, secret); err != nil {
+		return fmt.Errorf("failed to save tokens under service helicon with username %s: %w", username, err)
+	}
+	return keyringIndexProfile(username)
+}
+
+func (KeyringStore) Get(username string) (string, error) {
+	secret, err := keyring.Get("helicon", username)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tokens under service helicon with username %s: %w", username, err)
+	}
+	// profiles saved before the index existed are indexed the first time they are loaded.
+	if err = keyringIndexProfile(username); err != nil {
+		slog.Warn("failed to index profile", "username", username, "error", err)
+	}
+	return secret, nil
+}
+
+func (KeyringStore) Delete(username string) error {
+	if err := keyring.Delete("helicon", username); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete tokens under service helicon with username %s: %w", username, err)
+	}
+	profiles, err := KeyringStore{}.List()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(profiles, username) {
+		return nil
+	}
+	return keyringSetProfiles(slices.DeleteFunc(profiles, func(p string) bool { return p == username }))
+}
+
+// List returns usernames saved through this store. Profiles saved before the index existed are listed once they are
+// loaded through [KeyringStore.Get].
+func (KeyringStore) List() ([]string, error) {
+	index, err := keyring.Get("helicon", keyringProfilesUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile index under service helicon: %w", err)
+	}
+	if index == "" {
+		return nil, nil
+	}
+	return strings.Split(index, "\x1F"), nil
+}
+
+func keyringIndexProfile(username string) error {
+	profiles, err := KeyringStore{}.List()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(profiles, username) {
+		return nil
+	}
+	return keyringSetProfiles(append(profiles, username))
+}
+
+func keyringSetProfiles(profiles []string) error {
+	if len(profiles) == 0 {
+		if err := keyring.Delete("helicon", keyringProfilesUser); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("failed to delete profile index under service helicon: %w", err)
+		}
+		return nil
+	}
+	if err := keyring.Set("helicon", keyringProfilesUser, strings.Join(profiles, "\x1F")); err != nil {
+		return fmt.Errorf("failed to save profile index under service helicon: %w", err)
+	}
+	return nil
+}
+
+func (h *Helicon) tokenStore() TokenStore {
+	if h.TokenStore != nil {
+		return h.TokenStore
+	}
+	return KeyringStore{}
+}
+
+// SaveTokensToKeyring under service "helicon" (or into [Helicon.TokenStore] if set),
 //   - username => Twitter username
 //   - password => all tokens concatenated with \x1F (US)
 //
@@ -22,11 +125,7 @@ func (h *Helicon) SaveTokensToKeyring() error {
 		h.Cookies.BearerToken,
 	}
 	pass := base64.StdEncoding.EncodeToString([]byte(strings.Join(tokens, "\x1F")))
-	err := keyring.Set("helicon", h.Credentials.Username, pass)
-	if err != nil {
-		return fmt.Errorf("failed to save tokens under service helicon with username %s: %w", h.Credentials.Username, err)
-	}
-	return nil
+	return h.tokenStore().Set(h.Credentials.Username, pass)
 }
 
 // LoadTokensFromKeyring into Helicon struct, accessible from
@@ -34,8 +133,8 @@ func (h *Helicon) SaveTokensToKeyring() error {
 func (h *Helicon) LoadTokensFromKeyring() error {
 	var passEncoded string
 	var err error
-	if passEncoded, err = keyring.Get("helicon", h.Credentials.Username); err != nil {
-		return fmt.Errorf("failed to get tokens under service helicon with username %s: %w", h.Credentials.Username, err)
+	if passEncoded, err = h.tokenStore().Get(h.Credentials.Username); err != nil {
+		return err
 	}
 	passDecodedBytes, err := base64.StdEncoding.DecodeString(passEncoded)
 	if err != nil {
@@ -57,3 +156,9 @@ func (h *Helicon) LoadTokensFromKeyring() error {
 	h.Cookies.BearerToken = parts[2]
 	return nil
 }
+
+// DeleteTokensFromKeyring removes tokens of [TwitterCredentials.Username] from the store. Tokens stay valid on X's side,
+// see [Helicon.Logout] for that.
+func (h *Helicon) DeleteTokensFromKeyring() error {
+	return h.tokenStore().Delete(h.Credentials.Username)
+}
@@ -0,0 +1,53 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"github.com/zalando/go-keyring"
+	"slices"
+	"testing"
+)
+
+func TestKeyringStore_Profiles(t *testing.T) {
+	keyring.MockInit()
+	var store helicon.KeyringStore
+	for _, username := range []string{"first", "second", "first"} {
+		if err := store.Set(username, "tokens"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profiles, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(profiles, []string{"first", "second"}) {
+		t.Fatalf("unexpected profiles %v", profiles)
+	}
+	client := helicon.Helicon{Credentials: helicon.TwitterCredentials{Username: "second"}}
+	if err := client.DeleteTokensFromKeyring(); err != nil {
+		t.Fatal(err)
+	}
+	if profiles, _ = store.List(); !slices.Equal(profiles, []string{"first"}) {
+		t.Fatalf("unexpected profiles after delete %v", profiles)
+	}
+	if err := store.Delete("nobody"); err != nil {
+		t.Fatalf("deleting missing profile should be a no-op, got %v", err)
+	}
+}
+
+func TestKeyringStore_GetIndexes(t *testing.T) {
+	keyring.MockInit()
+	// saved before the profile index existed.
+	if err := keyring.Set("helicon", "legacy", "tokens"); err != nil {
+		t.Fatal(err)
+	}
+	var store helicon.KeyringStore
+	if profiles, _ := store.List(); len(profiles) != 0 {
+		t.Fatalf("unexpected profiles %v", profiles)
+	}
+	if _, err := store.Get("legacy"); err != nil {
+		t.Fatal(err)
+	}
+	if profiles, _ := store.List(); !slices.Equal(profiles, []string{"legacy"}) {
+		t.Fatalf("expected loaded profile to be indexed, got %v", profiles)
+	}
+}
@@ -0,0 +1,144 @@
+package helicon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LoginBlockedError is the common part of everything that stops a login flow and needs a human to look at it.
+// Every blocker error below embeds it and unwraps to it, so if you don't care which one it is
+//
+//	var blocked *helicon.LoginBlockedError
+//	if errors.As(err, &blocked) { alert(blocked.Message) }
+type LoginBlockedError struct {
+	// subtask X sent us instead of the one we were expecting, e.g. ArkoseLogin, DenyLoginSubtask.
+	// empty if blocker came from `errors` array.
+	SubtaskID string
+	// X error code, 0 if blocker came from a subtask.
+	Code int
+	// user-facing message from X, the one you would see in browser.
+	Message string
+}
+
+func (e *LoginBlockedError) Error() string {
+	var parts []string
+	if e.SubtaskID != "" {
+		parts = append(parts, "subtask "+e.SubtaskID)
+	}
+	if e.Code != 0 {
+		parts = append(parts, fmt.Sprintf("code %d", e.Code))
+	}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	return "login blocked: " + strings.Join(parts, ": ")
+}
+
+// ArkoseChallengeError is returned when X wants an Arkose / FunCaptcha solved. we can't solve it, human can, in browser.
+type ArkoseChallengeError struct{ LoginBlockedError }
+
+// AccountLockedError is returned when account is temporarily locked (code 326), or login is denied for suspicious activity.
+type AccountLockedError struct{ LoginBlockedError }
+
+// AccountSuspendedError is returned when account is suspended (code 64).
+type AccountSuspendedError struct{ LoginBlockedError }
+
+// WrongPasswordError is returned when X rejects the credentials (code 399). do NOT retry these, few more and account gets locked.
+type WrongPasswordError struct{ LoginBlockedError }
+
+// VerificationRequiredError is returned when X asks for something we don't have,
+// e.g. email / phone confirmation (LoginAcid) or a 2FA code (LoginTwoFactorAuthChallenge).
+type VerificationRequiredError struct{ LoginBlockedError }
+
+func (e *ArkoseChallengeError) Error() string {
+	return "arkose challenge required, " + e.LoginBlockedError.Error()
+}
+func (e *ArkoseChallengeError) Unwrap() error { return &e.LoginBlockedError }
+
+func (e *AccountLockedError) Error() string {
+	return "account is locked, " + e.LoginBlockedError.Error()
+}
+func (e *AccountLockedError) Unwrap() error { return &e.LoginBlockedError }
+
+func (e *AccountSuspendedError) Error() string {
+	return "account is suspended, " + e.LoginBlockedError.Error()
+}
+func (e *AccountSuspendedError) Unwrap() error { return &e.LoginBlockedError }
+
+func (e *WrongPasswordError) Error() string {
+	return "wrong username or password, " + e.LoginBlockedError.Error()
+}
+func (e *WrongPasswordError) Unwrap() error { return &e.LoginBlockedError }
+
+func (e *VerificationRequiredError) Error() string {
+	return "additional verification required, " + e.LoginBlockedError.Error()
+}
+func (e *VerificationRequiredError) Unwrap() error { return &e.LoginBlockedError }
+
+// ErrLoginNotCompleted is returned when every step of the flow went through, but X did not give us the session cookies.
+// happens every now and then for no apparent reason, [Helicon.Login] retries these up to [Helicon.MaxLoginAttempts] times.
+var ErrLoginNotCompleted = errors.New("login flow finished without session cookies")
+
+// LoginLoopError is returned from [LoginFlow.Next] when X asks again for a subtask that is already answered, username
+// or password for example. answering it the same way again would go around in circles.
+type LoginLoopError struct {
+	SubtaskID string
+	// user-facing message from X, if there is one.
+	Message string
+}
+
+func (e *LoginLoopError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("login flow asked for subtask %s again: %s", e.SubtaskID, e.Message)
+	}
+	return fmt.Sprintf("login flow asked for subtask %s again", e.SubtaskID)
+}
+
+// known error codes from onboarding/task.json.
+const (
+	onboardingCodeSuspended     = 64
+	onboardingCodeLocked        = 326
+	onboardingCodeWrongPassword = 399
+)
+
+type onboardingError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type onboardingResponse struct {
+	FlowToken string            `json:"flow_token"`
+	Status    string            `json:"status"`
+	Subtasks  []LoginSubtask    `json:"subtasks"`
+	Errors    []onboardingError `json:"errors"`
+}
+
+// blocker returns a typed error if this response is something we can't continue from, nil otherwise.
+// subtasks that just need more input (LoginAcid, 2FA) are not blockers here, see [LoginFlow.Next].
+func (r *onboardingResponse) blocker() error {
+	for _, e := range r.Errors {
+		base := LoginBlockedError{Code: e.Code, Message: e.Message}
+		switch e.Code {
+		case onboardingCodeSuspended:
+			return &AccountSuspendedError{base}
+		case onboardingCodeLocked:
+			return &AccountLockedError{base}
+		case onboardingCodeWrongPassword:
+			return &WrongPasswordError{base}
+		}
+	}
+	for _, subtask := range r.Subtasks {
+		base := LoginBlockedError{SubtaskID: subtask.SubtaskID, Message: subtask.Message()}
+		switch {
+		case strings.Contains(strings.ToLower(subtask.SubtaskID), "arkose"):
+			return &ArkoseChallengeError{base}
+		case subtask.SubtaskID == "DenyLoginSubtask":
+			if strings.Contains(strings.ToLower(base.Message), "suspended") {
+				return &AccountSuspendedError{base}
+			}
+			return &AccountLockedError{base}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package helicon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOnboardingResponse_Blocker(t *testing.T) {
+	t.Parallel()
+	denied := `{"subtask_id":"DenyLoginSubtask","cta":{"secondary_text":{"text":"%s"}}}`
+	cases := []struct {
+		name     string
+		response string
+		// pointer to a blocker type, nil if response is not a blocker.
+		target any
+	}{
+		{"suspended", `{"errors":[{"code":64,"message":"Your account is suspended and is not permitted to access this feature."}]}`, new(*AccountSuspendedError)},
+		{"locked", `{"errors":[{"code":326,"message":"To protect our users from spam and other malicious activity, this account is temporarily locked."}]}`, new(*AccountLockedError)},
+		{"wrong password", `{"errors":[{"code":399,"message":"Incorrect. Please try again."}]}`, new(*WrongPasswordError)},
+		{"arkose", `{"flow_token":"g;1","subtasks":[{"subtask_id":"ArkoseLogin","web_modal":{"primary_text":{"text":"Authenticate your account"}}}]}`, new(*ArkoseChallengeError)},
+		{"denied", `{"flow_token":"g;1","subtasks":[` + fmt.Sprintf(denied, "Suspicious login prevented") + `]}`, new(*AccountLockedError)},
+		{"denied suspended", `{"flow_token":"g;1","subtasks":[` + fmt.Sprintf(denied, "Your account is suspended") + `]}`, new(*AccountSuspendedError)},
+		{"unknown code", `{"errors":[{"code":366,"message":"flow name LoginFlow is currently not accessible"}]}`, nil},
+		{"needs input", `{"flow_token":"g;1","subtasks":[{"subtask_id":"LoginAcid"}]}`, nil},
+		{"next step", `{"flow_token":"g;1","subtasks":[{"subtask_id":"LoginEnterPassword"}]}`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			var response onboardingResponse
+			if err := json.Unmarshal([]byte(c.response), &response); err != nil {
+				t.Fatal(err)
+			}
+			err := response.blocker()
+			if c.target == nil {
+				if err != nil {
+					t.Fatalf("expected no blocker, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, c.target) {
+				t.Fatalf("expected %T, got %T %v", c.target, err, err)
+			}
+			var blocked *LoginBlockedError
+			if !errors.As(err, &blocked) || blocked.Message == "" {
+				t.Errorf("expected blocker to unwrap to LoginBlockedError with a message, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHelicon_RetryLogin(t *testing.T) {
+	t.Parallel()
+	var h Helicon
+	attempts := 0
+	err := h.retryLogin(func() error {
+		attempts++
+		return ErrLoginNotCompleted
+	})
+	if !errors.Is(err, ErrLoginNotCompleted) || attempts != DefaultMaxLoginAttempts {
+		t.Fatalf("expected %d attempts ending in ErrLoginNotCompleted, got %d, %v", DefaultMaxLoginAttempts, attempts, err)
+	}
+
+	h.MaxLoginAttempts = 5
+	attempts = 0
+	err = h.retryLogin(func() error {
+		attempts++
+		if attempts < 2 {
+			return ErrLoginNotCompleted
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("expected login to go through on second attempt, got %d, %v", attempts, err)
+	}
+
+	// retrying a wrong password is how accounts get locked.
+	attempts = 0
+	err = h.retryLogin(func() error {
+		attempts++
+		return &WrongPasswordError{LoginBlockedError{Code: onboardingCodeWrongPassword}}
+	})
+	var wrongPassword *WrongPasswordError
+	if !errors.As(err, &wrongPassword) || attempts != 1 {
+		t.Fatalf("blockers must not be retried, got %d attempts, %v", attempts, err)
+	}
+}
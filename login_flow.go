@@ -0,0 +1,219 @@
+package helicon
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// subtasks of the login flow that helicon knows how to answer.
+const (
+	SubtaskJsInstrumentation        = "LoginJsInstrumentationSubtask"
+	SubtaskEnterUserIdentifier      = "LoginEnterUserIdentifierSSO"
+	SubtaskEnterAlternateIdentifier = "LoginEnterAlternateIdentifierSubtask"
+	SubtaskEnterPassword            = "LoginEnterPassword"
+	SubtaskAcid                     = "LoginAcid"
+	SubtaskTwoFactorAuthChallenge   = "LoginTwoFactorAuthChallenge"
+	SubtaskLoginSuccess             = "LoginSuccessSubtask"
+)
+
+// LoginSubtask is one step X asks for in the login flow. Only the ID is decoded,
+// rest of the subtask is kept as is in Raw, shapes differ wildly between subtask types.
+type LoginSubtask struct {
+	SubtaskID string
+	Raw       json.RawMessage
+}
+
+func (s *LoginSubtask) UnmarshalJSON(data []byte) error {
+	var head struct {
+		SubtaskID string `json:"subtask_id"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return fmt.Errorf("failed to decode subtask: %w", err)
+	}
+	s.SubtaskID = head.SubtaskID
+	s.Raw = append(s.Raw[:0], data...)
+	return nil
+}
+
+func (s LoginSubtask) MarshalJSON() ([]byte, error) {
+	if len(s.Raw) == 0 {
+		return json.Marshal(map[string]string{"subtask_id": s.SubtaskID})
+	}
+	return s.Raw, nil
+}
+
+// Message digs the user facing text out of subtask, which lives under different keys for each subtask type,
+// but it is always a `secondary_text` or `primary_text` with a `text` inside.
+func (s LoginSubtask) Message() string {
+	var tree any
+	if err := json.Unmarshal(s.Raw, &tree); err != nil {
+		return ""
+	}
+	if msg := findText(tree, "secondary_text"); msg != "" {
+		return msg
+	}
+	return findText(tree, "primary_text")
+}
+
+// NeedsInput is true if this subtask can only be answered by a human, like an email confirmation or a 2FA code.
+func (s LoginSubtask) NeedsInput() bool {
+	switch s.SubtaskID {
+	case SubtaskEnterAlternateIdentifier, SubtaskAcid, SubtaskTwoFactorAuthChallenge:
+		return true
+	}
+	return false
+}
+
+func findText(node any, key string) string {
+	switch n := node.(type) {
+	case map[string]any:
+		if v, ok := n[key].(map[string]any); ok {
+			if text, ok := v["text"].(string); ok && text != "" {
+				return text
+			}
+		}
+		for _, child := range n {
+			if text := findText(child, key); text != "" {
+				return text
+			}
+		}
+	case []any:
+		for _, child := range n {
+			if text := findText(child, key); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// ResumeLoginFlow restores a flow saved with [json.Marshal]. Every field needed to continue is serialized
+// (flow token, guest token, att, guest_id, __cf_bm, user agent, pending subtask), so a flow started in one
+// process can be continued in another, e.g. a web UI that shows the 2FA prompt and continues minutes later
+//
+//	flow, _ := h.StartLoginFlow()
+//	pending, _ := flow.Next(h)      // runs JS challenge, username and password
+//	saved, _ := json.Marshal(flow)  // store it, show pending.Message() to user
+//	...
+//	flow, _ = helicon.ResumeLoginFlow(saved)
+//	_ = flow.Submit(h, codeFromUser)
+//	pending, _ = flow.Next(h)       // nil once h.Cookies are set
+func ResumeLoginFlow(data []byte) (*LoginFlow, error) {
+	var flow LoginFlow
+	if err := json.Unmarshal(data, &flow); err != nil {
+		return nil, fmt.Errorf("failed to decode login flow: %w", err)
+	}
+	if flow.FlowToken == "" || flow.GuestToken == "" || flow.AnonymousBearerToken == "" {
+		return nil, fmt.Errorf("login flow is missing flow token, guest token or bearer token")
+	}
+	return &flow, nil
+}
+
+// Next drives the flow forward as far as it can go without outside input. JS instrumentation is solved, username and
+// password are submitted if they are set in [Helicon.Credentials].
+//
+// Returns the subtask that needs input (answer it with [LoginFlow.Submit]), or nil once the flow is completed
+// and session cookies are set on h. Blockers are returned as errors, see [LoginBlockedError]. Every subtask is answered
+// once per call, [LoginLoopError] is returned if X asks for one of them again.
+func (f *LoginFlow) Next(h *Helicon) (*LoginSubtask, error) {
+	answered := map[string]bool{}
+	for !f.Completed {
+		if f.Pending == nil {
+			return nil, ErrLoginNotCompleted
+		}
+		if answered[f.Pending.SubtaskID] {
+			return nil, &LoginLoopError{SubtaskID: f.Pending.SubtaskID, Message: f.Pending.Message()}
+		}
+		answered[f.Pending.SubtaskID] = true
+		var err error
+		switch f.Pending.SubtaskID {
+		case SubtaskJsInstrumentation:
+			err = f.SolveAndSubmitJSChallenge(h)
+		case SubtaskEnterUserIdentifier:
+			if h.Credentials.Username == "" {
+				return f.Pending, nil
+			}
+			err = f.Submit(h, h.Credentials.Username)
+		case SubtaskEnterPassword:
+			if h.Credentials.Password == "" {
+				return f.Pending, nil
+			}
+			err = f.Submit(h, h.Credentials.Password)
+		default:
+			return f.Pending, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil //nolint:nilnil // nil subtask is how we say flow is completed
+}
+
+// Submit answers the pending subtask with input and moves the flow one step. What input is depends on the subtask,
+// username for [SubtaskEnterUserIdentifier], password for [SubtaskEnterPassword], the code for [SubtaskAcid] and
+// [SubtaskTwoFactorAuthChallenge], and solution of the challenge for [SubtaskJsInstrumentation].
+func (f *LoginFlow) Submit(h *Helicon, input string) error {
+	if f.Completed {
+		return fmt.Errorf("login flow is already completed")
+	}
+	if f.Pending == nil {
+		return fmt.Errorf("login flow has no pending subtask")
+	}
+	return f.submitSubtask(h, f.Pending.SubtaskID, input)
+}
+
+func (f *LoginFlow) submitSubtask(h *Helicon, subtaskID string, input string) error {
+	subtaskInput := map[string]any{"subtask_id": subtaskID}
+	switch subtaskID {
+	case SubtaskJsInstrumentation:
+		subtaskInput["js_instrumentation"] = map[string]string{"response": input, "link": "next_link"}
+	case SubtaskEnterUserIdentifier:
+		subtaskInput["settings_list"] = SettingsList{
+			Link: "next_link",
+			SettingResponses: []SettingResponses{
+				{Key: "user_identifier", ResponseData: ResponseData{TextData: TextData{Result: input}}},
+			},
+		}
+	case SubtaskEnterPassword:
+		subtaskInput["enter_password"] = EnterPassword{Password: input, Link: "next_link"}
+	case SubtaskEnterAlternateIdentifier, SubtaskAcid, SubtaskTwoFactorAuthChallenge:
+		subtaskInput["enter_text"] = map[string]string{"text": input, "link": "next_link"}
+	default:
+		return fmt.Errorf("don't know how to answer subtask %s", subtaskID)
+	}
+	taskResponse, resp, err := f.postTask(map[string]any{
+		"flow_token":     f.FlowToken,
+		"subtask_inputs": []any{subtaskInput},
+	})
+	if err != nil {
+		return err
+	}
+	f.FlowToken = taskResponse.FlowToken
+	f.Status = taskResponse.Status
+	f.Pending = nil
+	if len(taskResponse.Subtasks) > 0 {
+		f.Pending = &taskResponse.Subtasks[0]
+	}
+	if h.setSessionCookies(resp) {
+		f.Completed = true
+		f.Pending = nil
+	}
+	return nil
+}
+
+func (f *LoginFlow) jsInstrumentationUrl() string {
+	if f.Pending != nil && f.Pending.SubtaskID == SubtaskJsInstrumentation {
+		var subtask struct {
+			JsInstrumentation struct {
+				Url string `json:"url"`
+			} `json:"js_instrumentation"`
+		}
+		if err := json.Unmarshal(f.Pending.Raw, &subtask); err == nil && subtask.JsInstrumentation.Url != "" {
+			return subtask.JsInstrumentation.Url
+		}
+	}
+	if len(f.Subtasks) > 0 {
+		return f.Subtasks[0].JsInstrumentation.Url
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package helicon_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/caner-cetin/helicon"
+	"net/http"
+	"testing"
+)
+
+func TestResumeLoginFlow(t *testing.T) {
+	t.Parallel()
+	pending := `{"subtask_id":"LoginAcid","enter_text":{"header":{"primary_text":{"text":"Enter your email"},"secondary_text":{"text":"Check your email for a confirmation code."}}}}`
+	flow := helicon.LoginFlow{
+		FlowToken:            "g;123:-456:0",
+		AnonymousBearerToken: "Bearer anonymous",
+		GuestToken:           "1900",
+		GuestId:              "v1%3A1234",
+		UserAgent:            "Mozilla/5.0",
+		Att:                  "1-att",
+		CFBM:                 "cfbm",
+	}
+	flow.Pending = &helicon.LoginSubtask{}
+	if err := json.Unmarshal([]byte(pending), flow.Pending); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := json.Marshal(flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := helicon.ResumeLoginFlow(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.FlowToken != flow.FlowToken || resumed.Att != flow.Att || resumed.CFBM != flow.CFBM || resumed.GuestId != flow.GuestId {
+		t.Fatalf("flow not restored: %+v", resumed)
+	}
+	if resumed.Pending == nil || resumed.Pending.SubtaskID != helicon.SubtaskAcid || !resumed.Pending.NeedsInput() {
+		t.Fatalf("pending subtask not restored: %+v", resumed.Pending)
+	}
+	if msg := resumed.Pending.Message(); msg != "Check your email for a confirmation code." {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if _, err := helicon.ResumeLoginFlow([]byte(`{"flow_token":"x"}`)); err == nil {
+		t.Fatal("expected incomplete flow to be rejected")
+	}
+}
+
+func TestLoginFlow_Next_Loop(t *testing.T) {
+	posts := 0
+	helicon.StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		posts++
+		// username is accepted, and asked for again.
+		return helicon.StubResponse(r, http.StatusOK, `{"flow_token":"g;2","status":"success","subtasks":[
+			{"subtask_id":"LoginEnterUserIdentifierSSO","settings_list":{"header":{"secondary_text":{"text":"Enter your phone number, email address or username"}}}}]}`), nil
+	})
+	h := &helicon.Helicon{Credentials: helicon.TwitterCredentials{Username: "alice", Password: "hunter2"}}
+	flow := &helicon.LoginFlow{FlowToken: "g;1", Pending: &helicon.LoginSubtask{SubtaskID: helicon.SubtaskEnterUserIdentifier}}
+	pending, err := flow.Next(h)
+	var loop *helicon.LoginLoopError
+	if !errors.As(err, &loop) || loop.SubtaskID != helicon.SubtaskEnterUserIdentifier || pending != nil {
+		t.Fatalf("expected a loop error, got %v, %+v", err, pending)
+	}
+	if loop.Message != "Enter your phone number, email address or username" || posts != 1 {
+		t.Errorf("unexpected loop error %q after %d requests", loop.Message, posts)
+	}
+}
+
+func TestLoginFlow_SubmitUsernameAndPassword(t *testing.T) {
+	var subtasks []string
+	helicon.StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			SubtaskInputs []struct {
+				SubtaskID string `json:"subtask_id"`
+			} `json:"subtask_inputs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		subtasks = append(subtasks, body.SubtaskInputs[0].SubtaskID)
+		// X asks for the email before the password.
+		return helicon.StubResponse(r, http.StatusOK, `{"flow_token":"g;2","status":"success","subtasks":[
+			{"subtask_id":"LoginEnterAlternateIdentifierSubtask","enter_text":{"header":{"secondary_text":{"text":"Enter your phone number or email address"}}}}]}`), nil
+	})
+	h := &helicon.Helicon{Credentials: helicon.TwitterCredentials{Username: "alice", Password: "hunter2"}}
+	flow := &helicon.LoginFlow{FlowToken: "g;1", Pending: &helicon.LoginSubtask{SubtaskID: helicon.SubtaskEnterUserIdentifier}}
+	err := flow.SubmitUsernameAndPassword(h)
+	var verification *helicon.VerificationRequiredError
+	if !errors.As(err, &verification) || verification.SubtaskID != helicon.SubtaskEnterAlternateIdentifier {
+		t.Fatalf("expected verification of alternate identifier, got %v", err)
+	}
+	if len(subtasks) != 1 || subtasks[0] != helicon.SubtaskEnterUserIdentifier {
+		t.Errorf("password must not be submitted to another subtask, submitted %v", subtasks)
+	}
+}
@@ -0,0 +1,101 @@
+package helicon
+
+import (
+	"cmp"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// PhotoSize is `name` parameter of pbs.twimg.com image URLs.
+type PhotoSize string
+
+const (
+	PhotoThumb  PhotoSize = "thumb"
+	PhotoSmall  PhotoSize = "small"
+	PhotoMedium PhotoSize = "medium"
+	PhotoLarge  PhotoSize = "large"
+	// PhotoOriginal is the image as it was uploaded, largest there is.
+	PhotoOriginal PhotoSize = "orig"
+)
+
+// PhotoURL returns URL of the image in size, thumbnail of the video for videos and GIFs.
+//
+//	https://pbs.twimg.com/media/GPhoto1.jpg => https://pbs.twimg.com/media/GPhoto1?format=jpg&name=orig
+func (m *Media) PhotoURL(size PhotoSize) string {
+	u, err := url.Parse(m.URL)
+	if err != nil || m.URL == "" {
+		return m.URL
+	}
+	query := u.Query()
+	if ext := path.Ext(u.Path); ext != "" {
+		u.Path = strings.TrimSuffix(u.Path, ext)
+		query.Set("format", ext[1:])
+	}
+	query.Set("name", string(size))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// OriginalURL is [Media.PhotoURL] of [PhotoOriginal].
+func (m *Media) OriginalURL() string {
+	return m.PhotoURL(PhotoOriginal)
+}
+
+// BestVideo returns the MP4 variant with the highest bitrate, false for photos. HLS playlists are skipped, they need a
+// player to be of use.
+func (m *Media) BestVideo() (MediaVariant, bool) {
+	var best MediaVariant
+	found := false
+	for _, variant := range m.Variants {
+		if variant.ContentType == "video/mp4" && (!found || variant.Bitrate > best.Bitrate) {
+			best, found = variant, true
+		}
+	}
+	return best, found
+}
+
+// Media returns media of every tweet in the set, in order of tweets. media shared by tweets is there once.
+func (s *TweetSet) Media() []*Media {
+	var media []*Media
+	seen := map[string]bool{}
+	for _, tweet := range s.Tweets {
+		for _, m := range tweet.Media {
+			key := cmp.Or(m.Key, m.ID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			media = append(media, m)
+		}
+	}
+	return media
+}
+
+func (raw RawMedia) media() *Media {
+	media := &Media{
+		ID:          raw.IdStr,
+		Key:         raw.MediaKey,
+		Type:        MediaType(raw.Type),
+		URL:         raw.MediaUrlHttps,
+		ShortURL:    raw.Url,
+		ExpandedURL: raw.ExpandedUrl,
+		Width:       raw.OriginalInfo.Width,
+		Height:      raw.OriginalInfo.Height,
+		AltText:     raw.ExtAltText,
+	}
+	if info := raw.VideoInfo; info != nil {
+		if len(info.AspectRatio) == 2 {
+			media.AspectRatio = [2]int{info.AspectRatio[0], info.AspectRatio[1]}
+		}
+		media.Duration = time.Duration(info.DurationMillis) * time.Millisecond
+		for _, variant := range info.Variants {
+			media.Variants = append(media.Variants, MediaVariant{URL: variant.Url, ContentType: variant.ContentType, Bitrate: variant.Bitrate})
+		}
+	}
+	if availability := raw.ExtMediaAvailability; availability != nil && availability.Status != "Available" {
+		media.Unavailable = cmp.Or(availability.Reason, availability.Status)
+	}
+	return media
+}
@@ -0,0 +1,40 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"testing"
+	"time"
+)
+
+func TestMedia(t *testing.T) {
+	t.Parallel()
+	set := extractFixture(t)
+	media := set.Media()
+	if len(media) != 2 {
+		t.Fatalf("expected 2 media in the conversation, got %d", len(media))
+	}
+	photo, video := media[0], media[1]
+
+	if got := photo.OriginalURL(); got != "https://pbs.twimg.com/media/GPhoto1?format=jpg&name=orig" {
+		t.Errorf("unexpected original URL %s", got)
+	}
+	if got := (&helicon.Media{URL: "https://pbs.twimg.com/media/GPhoto1?format=png&name=small"}).PhotoURL(helicon.PhotoLarge); got != "https://pbs.twimg.com/media/GPhoto1?format=png&name=large" {
+		t.Errorf("unexpected large URL %s", got)
+	}
+	if _, ok := photo.BestVideo(); ok {
+		t.Error("photos have no video")
+	}
+
+	if video.Type != helicon.MediaVideo || video.Duration != 12345*time.Millisecond || video.AspectRatio != [2]int{16, 9} || len(video.Variants) != 3 {
+		t.Errorf("unexpected video %+v", video)
+	}
+	best, ok := video.BestVideo()
+	if !ok || best.Bitrate != 2176000 || best.URL != "https://video.twimg.com/amplify_video/1799999999999999991/vid/1280x720/high.mp4" {
+		t.Errorf("unexpected best variant %+v", best)
+	}
+
+	gif := helicon.Media{Type: helicon.MediaAnimatedGIF, Variants: []helicon.MediaVariant{{URL: "https://video.twimg.com/tweet_video/a.mp4", ContentType: "video/mp4"}}}
+	if best, ok := gif.BestVideo(); !ok || best.URL != "https://video.twimg.com/tweet_video/a.mp4" {
+		t.Errorf("GIFs have a single MP4 without bitrate, got %+v", best)
+	}
+}
@@ -0,0 +1,146 @@
+package helicon
+
+import "time"
+
+// Tweet is a tweet flattened out of the GraphQL response, see [ExtractTweets]. Relationships are resolved within the
+// same [TweetSet], pointers are nil if the other end was not in the response, IDs are always there.
+type Tweet struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	AuthorID       string    `json:"author_id"`
+	Author         *User     `json:"author,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	// full_text as X sends it, HTML escaped, with reply mentions and t.co links, see [Tweet.PlainText].
+	Text string `json:"text"`
+	// code points of Text web client shows, zero if all of it.
+	DisplayTextRange [2]int   `json:"display_text_range,omitzero"`
+	Entities         Entities `json:"entities"`
+	Lang             string   `json:"lang,omitempty"`
+	// name of the app tweet was sent from, "Twitter for iPhone", "Twitter Web App"...
+	Source string `json:"source,omitempty"`
+
+	InReplyToID     string `json:"in_reply_to_id,omitempty"`
+	InReplyToUserID string `json:"in_reply_to_user_id,omitempty"`
+	// tweet and user this one replies to, and replies to this one, if they are in the same set.
+	// not marshalled, they point back to each other.
+	InReplyTo     *Tweet   `json:"-"`
+	InReplyToUser *User    `json:"-"`
+	Replies       []*Tweet `json:"-"`
+	// quoted tweet of quote tweets and retweeted tweet of retweets, nil if it is unavailable or nested too deep, see
+	// [ExtractTweets]. IDs are there either way.
+	QuotedTweetID    string `json:"quoted_tweet_id,omitempty"`
+	QuotedTweet      *Tweet `json:"quoted_tweet,omitempty"`
+	RetweetedTweetID string `json:"retweeted_tweet_id,omitempty"`
+	RetweetedTweet   *Tweet `json:"retweeted_tweet,omitempty"`
+	// mentioned users, the ones that are not in the set only have ID, name and screen name.
+	Mentions []*User  `json:"mentions,omitempty"`
+	Media    []*Media `json:"media,omitempty"`
+	Place    *Place   `json:"place,omitempty"`
+	// long tweet, Text and Entities are of the whole note, not its first 280 characters.
+	Note bool `json:"note,omitempty"`
+	// bold and italic parts of note tweets.
+	RichTextTags []RichTextTag `json:"rich_text_tags,omitempty"`
+	// media of note tweets put between the text, not below it.
+	InlineMedia []InlineMedia `json:"inline_media,omitempty"`
+	Article     *Article      `json:"article,omitempty"`
+	// link preview or poll.
+	Card *Card `json:"card,omitempty"`
+	// tweet has Community Notes, CommunityNote is the one that is shown, nil if none of them is.
+	HasCommunityNotes bool                  `json:"has_community_notes,omitempty"`
+	CommunityNote     *CommunityNoteSummary `json:"community_note,omitempty"`
+
+	ReplyCount    int `json:"reply_count"`
+	RetweetCount  int `json:"retweet_count"`
+	QuoteCount    int `json:"quote_count"`
+	LikeCount     int `json:"like_count"`
+	BookmarkCount int `json:"bookmark_count"`
+	// zero if X does not show views of the tweet, see ViewsEnabled.
+	ViewCount    int  `json:"view_count"`
+	ViewsEnabled bool `json:"views_enabled"`
+
+	// state of the logged-in account.
+	Liked      bool `json:"liked"`
+	Retweeted  bool `json:"retweeted"`
+	Bookmarked bool `json:"bookmarked"`
+
+	PossiblySensitive bool `json:"possibly_sensitive"`
+	// every version of the tweet, oldest first, only one element if it was never edited.
+	EditIDs       []string  `json:"edit_ids,omitempty"`
+	EditableUntil time.Time `json:"editable_until,omitzero"`
+}
+
+// User is an account flattened out of the GraphQL response, see [ExtractTweets].
+type User struct {
+	ID          string `json:"id"`
+	ScreenName  string `json:"screen_name"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+	// website on the profile, t.co link expanded.
+	URL              string    `json:"url,omitempty"`
+	ProfileImageURL  string    `json:"profile_image_url,omitempty"`
+	ProfileBannerURL string    `json:"profile_banner_url,omitempty"`
+	CreatedAt        time.Time `json:"created_at,omitzero"`
+
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+	TweetCount     int `json:"tweet_count"`
+	LikeCount      int `json:"like_count"`
+	ListedCount    int `json:"listed_count"`
+	MediaCount     int `json:"media_count"`
+
+	// legacy verification, notable accounts before Twitter Blue.
+	Verified       bool     `json:"verified"`
+	BlueVerified   bool     `json:"blue_verified"`
+	Protected      bool     `json:"protected"`
+	PinnedTweetIDs []string `json:"pinned_tweet_ids,omitempty"`
+}
+
+type MediaType string
+
+const (
+	MediaPhoto       MediaType = "photo"
+	MediaVideo       MediaType = "video"
+	MediaAnimatedGIF MediaType = "animated_gif"
+)
+
+// Media is a photo, video or GIF attached to a tweet.
+type Media struct {
+	ID   string    `json:"id"`
+	Key  string    `json:"key"`
+	Type MediaType `json:"type"`
+	// image itself for photos, thumbnail for videos and GIFs.
+	URL string `json:"url"`
+	// t.co link at the end of tweet text, and where it goes.
+	ShortURL    string `json:"short_url,omitempty"`
+	ExpandedURL string `json:"expanded_url,omitempty"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	AltText     string `json:"alt_text,omitempty"`
+	// videos and GIFs only. GIFs are MP4s too, with a single variant.
+	AspectRatio [2]int         `json:"aspect_ratio,omitzero"`
+	Duration    time.Duration  `json:"duration,omitempty"`
+	Variants    []MediaVariant `json:"variants,omitempty"`
+	// why media is not shown, empty if it is available. "Unavailable" for copyright takedowns for example.
+	Unavailable string `json:"unavailable,omitempty"`
+}
+
+// MediaVariant is one encoding of a video, see [Media.BestVideo].
+type MediaVariant struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	// bits per second, zero for HLS playlists and GIFs.
+	Bitrate int `json:"bitrate,omitempty"`
+}
+
+// Place is the location a tweet is tagged with.
+type Place struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Type        string `json:"type"`
+	Country     string `json:"country"`
+	CountryCode string `json:"country_code"`
+	// corners of the bounding box as longitude, latitude pairs.
+	BoundingBox [][2]float64 `json:"bounding_box,omitempty"`
+}
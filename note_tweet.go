@@ -0,0 +1,63 @@
+package helicon
+
+import (
+	"html"
+	"strings"
+)
+
+// RawNoteTweet is `note_tweet_results.result`, the whole text of a long tweet. indices of its entities and tags are of
+// code points, like the ones of legacy full_text.
+type RawNoteTweet struct {
+	Id        string   `json:"id"`
+	Text      string   `json:"text"`
+	EntitySet Entities `json:"entity_set"`
+	Richtext  struct {
+		RichtextTags []RichTextTag `json:"richtext_tags,omitempty"`
+	} `json:"richtext"`
+	Media struct {
+		InlineMedia []InlineMedia `json:"inline_media,omitempty"`
+	} `json:"media"`
+}
+
+// RichTextTag styles text[FromIndex:ToIndex] of a note tweet, "Bold", "Italic" or both.
+type RichTextTag struct {
+	FromIndex     int      `json:"from_index"`
+	ToIndex       int      `json:"to_index"`
+	RichtextTypes []string `json:"richtext_types"`
+}
+
+// InlineMedia is a media of a note tweet that is shown at Index of its text, code points. media itself is in
+// [Tweet.Media].
+type InlineMedia struct {
+	MediaId string `json:"media_id"`
+	Index   int    `json:"index"`
+}
+
+// inserts are the rich text tags and inline media of a note tweet.
+func (t *Tweet) inserts() []textInsert {
+	var inserts []textInsert
+	for _, tag := range t.RichTextTags {
+		for _, style := range tag.RichtextTypes {
+			inserts = append(inserts, styleInserts(tag.FromIndex, tag.ToIndex, style)...)
+		}
+	}
+	for _, inline := range t.InlineMedia {
+		for _, media := range t.Media {
+			if media.ID == inline.MediaId {
+				inserts = append(inserts, mediaInsert(inline.Index, media.OriginalURL(), media.AltText))
+				break
+			}
+		}
+	}
+	return inserts
+}
+
+// mediaInsert puts image, thumbnail of videos, on its own line.
+func mediaInsert(at int, src, alt string) textInsert {
+	return textInsert{
+		at:       at,
+		kind:     insertMedia,
+		markdown: "\n![" + markdownEscapes.Replace(alt) + "](" + markdownURL.Replace(src) + ")\n",
+		html:     `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(strings.TrimSpace(alt)) + `">`,
+	}
+}
@@ -0,0 +1,41 @@
+package helicon_test
+
+import (
+	"github.com/caner-cetin/helicon"
+	"testing"
+)
+
+func TestExtractTweets_NoteTweet(t *testing.T) {
+	t.Parallel()
+	set, err := helicon.ExtractTweets([]byte(`{"tweet_results":{"result":{"__typename":"Tweet","rest_id":"1",
+		"legacy":{"id_str":"1","full_text":"Go 1.24 is out, truncated… https://t.co/note","display_text_range":[0,26],
+			"entities":{"urls":[{"url":"https://t.co/note","expanded_url":"https://x.com/i/web/status/1","display_url":"x.com/i/web/status/1","indices":[27,44]}]},
+			"extended_entities":{"media":[{"id_str":"9","media_key":"3_9","type":"photo","media_url_https":"https://pbs.twimg.com/media/GInline.jpg","ext_alt_text":"gopher"}]}},
+		"note_tweet":{"is_expandable":true,"note_tweet_results":{"result":{"id":"Tm90ZVR3ZWV0OjE=",
+			"text":"Go 1.24 is out, with generic type aliases.\nThanks @rsc\nRead more at https://t.co/blog",
+			"entity_set":{"urls":[{"url":"https://t.co/blog","expanded_url":"https://go.dev/blog/go1.24","display_url":"go.dev/blog/go1.24","indices":[68,85]}],
+				"user_mentions":[{"id_str":"7","screen_name":"rsc","name":"Russ Cox","indices":[50,54]}]},
+			"richtext":{"richtext_tags":[{"from_index":0,"to_index":7,"richtext_types":["Bold"]},{"from_index":21,"to_index":41,"richtext_types":["Bold","Italic"]}]},
+			"media":{"inline_media":[{"media_id":"9","index":43}]}}}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweet := set.Tweet("1")
+	if !tweet.Note || len(tweet.Mentions) != 1 || tweet.Mentions[0].ScreenName != "rsc" {
+		t.Fatalf("expected the whole note, got %+v", tweet)
+	}
+	if got, want := tweet.PlainText(), "Go 1.24 is out, with generic type aliases.\nThanks @rsc\nRead more at https://go.dev/blog/go1.24"; got != want {
+		t.Errorf("plain text\n got: %s\nwant: %s", got, want)
+	}
+	if got, want := tweet.Markdown(), "**Go 1.24** is out, with **_generic type aliases_**.  \n"+
+		"\n![gopher](https://pbs.twimg.com/media/GInline?format=jpg&name=orig)\n"+
+		"Thanks [@rsc](https://x.com/rsc)  \nRead more at [go.dev/blog/go1.24](https://go.dev/blog/go1.24)"; got != want {
+		t.Errorf("markdown\n got: %s\nwant: %s", got, want)
+	}
+	if got, want := tweet.HTML(), "<strong>Go 1.24</strong> is out, with <strong><em>generic type aliases</em></strong>.<br>\n"+
+		`<img src="https://pbs.twimg.com/media/GInline?format=jpg&amp;name=orig" alt="gopher">`+
+		`Thanks <a href="https://x.com/rsc" rel="noopener noreferrer nofollow">@rsc</a><br>`+"\n"+
+		`Read more at <a href="https://go.dev/blog/go1.24" rel="noopener noreferrer nofollow">go.dev/blog/go1.24</a>`; got != want {
+		t.Errorf("html\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+package helicon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+type OperationType string
+
+const (
+	// OperationQuery is sent as GET, everything is in query string.
+	OperationQuery OperationType = "query"
+	// OperationMutation is sent as POST with a JSON body, X requires this for writes.
+	OperationMutation OperationType = "mutation"
+)
+
+// Operation is any GraphQL operation of the web client. You don't need a typed wrapper to call something,
+// name, variables and a struct to decode `data` into is enough
+//
+//	var data struct {
+//		User struct {
+//			Result struct {
+//				RestId string `json:"rest_id"`
+//			} `json:"result"`
+//		} `json:"user"`
+//	}
+//	err := h.Execute(ctx, helicon.Operation{
+//		Name:      "UserByScreenName",
+//		Variables: map[string]any{"screen_name": "jack"},
+//	}, &data)
+type Operation struct {
+	Name string
+	// query ID known at the time of writing. ID discovered by [QueryRegistry] takes precedence, this is the fallback.
+	// can be empty if registry knows the operation.
+	QueryID string
+	// zero means whatever registry says, and [OperationQuery] if registry does not know either.
+	Type OperationType
+	// anything that marshals to a JSON object.
+	Variables any
+	// sent on top of the switches discovered for the operation, see [QueryRegistry.OperationFeatures].
+	Features FeatureSet
+	// anything that marshals to a JSON object, nil to not send at all.
+	FieldToggles any
+}
+
+// GraphQLError is one element of `errors` array of a GraphQL response.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Code       int            `json:"code,omitempty"`
+	Kind       string         `json:"kind,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors is returned from [Helicon.Execute] when response has errors and no data.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, graphQLError := range e {
+		messages = append(messages, graphQLError.Message)
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+// Execute runs op and decodes `data` of the response into out, out can be nil if you don't care about the response.
+//
+// Queries are sent as GET and mutations as POST, with the same headers every other request uses. Requests are healed
+// like [Helicon.GetTweetDetails] does, see [Helicon.OnHeal]. Errors that come along with data are logged, response is
+// still decoded; if there is no data, errors are returned as [GraphQLErrors]. See [Helicon.StrictDecoding] to fail on
+// responses that do not fit into out, and [WithEnvelope] to keep everything else X sent. Queries are served from
+// [Helicon.Cache] if it is set.
+func (h *Helicon) Execute(ctx context.Context, op Operation, out any, opts ...ExecuteOption) error {
+	var options executeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	env := options.envelope
+	if env != nil {
+		*env = Envelope{Operation: op.Name}
+	}
+	if op.Name == "" {
+		return fmt.Errorf("operation name is required")
+	}
+	send := func(ctx context.Context) (*httpResponse, error) {
+		return graphQLSend(h, op.Name, op.QueryID, func(queryID string, discovered FeatureSet) (*http.Request, error) {
+			return h.newGraphQLRequest(ctx, op, queryID, discovered)
+		}, h.roundTrip)
+	}
+	var resp *httpResponse
+	var err error
+	if options.noCache {
+		resp, err = send(ctx)
+	} else {
+		resp, err = h.cachedRoundTrip(ctx, op, send)
+	}
+	if resp == nil {
+		return err
+	}
+	// error statuses have errors in body too, envelope gets them either way.
+	var response graphQLResponse
+	decodeErr := json.Unmarshal(resp.body, &response)
+	if env != nil {
+		env.Raw, env.HTTP = resp.body, resp.meta
+		env.Data, env.Errors = response.Data, response.Errors
+	}
+	if err != nil {
+		return err
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode %s response: %w", op.Name, decodeErr)
+	}
+	hasData := len(response.Data) > 0 && !bytes.Equal(response.Data, []byte("null"))
+	if !hasData {
+		if len(response.Errors) > 0 {
+			return GraphQLErrors(response.Errors)
+		}
+		return fmt.Errorf("%s response has neither data nor errors", op.Name)
+	}
+	if len(response.Errors) > 0 {
+		slog.Warn("GraphQL response has errors along with data", "operation", op.Name, "errors", GraphQLErrors(response.Errors).Error())
+	}
+	if out == nil {
+		return nil
+	}
+	if h.StrictDecoding {
+		if err := strictDecode(op.Name, response.Data, reflect.TypeOf(out)); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(response.Data, out); err != nil {
+		return fmt.Errorf("failed to decode %s data: %w", op.Name, err)
+	}
+	return nil
+}
+
+// operationType is type of op, from registry if op does not say, [OperationQuery] if registry does not know either.
+func (h *Helicon) operationType(op Operation) OperationType {
+	if op.Type != "" {
+		return op.Type
+	}
+	info, _ := h.queryRegistry().Operation(op.Name)
+	if info.OperationType == string(OperationMutation) {
+		return OperationMutation
+	}
+	return OperationQuery
+}
+
+func (h *Helicon) newGraphQLRequest(ctx context.Context, op Operation, queryID string, discovered FeatureSet) (*http.Request, error) {
+	operationType := h.operationType(op)
+	features := discovered.Merge(op.Features)
+	endpoint := fmt.Sprintf("https://x.com/i/api/graphql/%s/%s", queryID, op.Name)
+	if operationType == OperationMutation {
+		payload := map[string]any{"variables": op.Variables, "queryId": queryID}
+		if len(features) > 0 {
+			payload["features"] = features
+		}
+		if op.FieldToggles != nil {
+			payload["fieldToggles"] = op.FieldToggles
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling %s body: %w", op.Name, err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct POST request for %s: %w", op.Name, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}
+	uri, err := graphQLURL(endpoint, op.Variables, features, op.FieldToggles)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct GET request for %s: %w", op.Name, err)
+	}
+	return req, nil
+}
+
+// graphQLURL builds the query string of a GET operation. features and fieldToggles are left out if they are empty.
+func graphQLURL(endpoint string, variables any, features FeatureSet, fieldToggles any) (string, error) {
+	if variables == nil {
+		variables = map[string]any{}
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling variables: %w", err)
+	}
+	fullURL := fmt.Sprintf("%s?variables=%s", endpoint, url.QueryEscape(string(variablesJSON)))
+	if len(features) > 0 {
+		featuresJSON, err := json.Marshal(features)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling features: %w", err)
+		}
+		fullURL = fmt.Sprintf("%s&features=%s", fullURL, url.QueryEscape(string(featuresJSON)))
+	}
+	if fieldToggles != nil {
+		fieldTogglesJSON, err := json.Marshal(fieldToggles)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling fieldToggles: %w", err)
+		}
+		fullURL = fmt.Sprintf("%s&fieldToggles=%s", fullURL, url.QueryEscape(string(fieldTogglesJSON)))
+	}
+	return fullURL, nil
+}
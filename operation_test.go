@@ -0,0 +1,52 @@
+package helicon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestNewGraphQLRequest(t *testing.T) {
+	t.Parallel()
+	h := Helicon{QueryRegistry: &QueryRegistry{}}
+	h.QueryRegistry.Set(OperationInfo{QueryID: "create", OperationName: "CreateTweet", OperationType: "mutation"})
+	discovered := FeatureSet{"a": false}
+
+	query := Operation{Name: "UserByScreenName", Variables: map[string]any{"screen_name": "jack"}, Features: FeatureSet{"a": true}}
+	req, err := h.newGraphQLRequest(context.Background(), query, "user", discovered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodGet || req.URL.Path != "/i/api/graphql/user/UserByScreenName" {
+		t.Fatalf("unexpected query request %s %s", req.Method, req.URL)
+	}
+	if got := req.URL.Query().Get("features"); got != `{"a":true}` {
+		t.Errorf("features must override discovered ones, got %s", got)
+	}
+	if req.URL.Query().Has("fieldToggles") {
+		t.Error("nil field toggles must not be sent")
+	}
+
+	mutation := Operation{Name: "CreateTweet", Variables: map[string]any{"tweet_text": "hello"}}
+	req, err = h.newGraphQLRequest(context.Background(), mutation, "create", discovered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("mutation type from registry must be sent as JSON POST, got %s", req.Method)
+	}
+	raw, _ := io.ReadAll(req.Body)
+	var body struct {
+		QueryID   string            `json:"queryId"`
+		Variables map[string]string `json:"variables"`
+		Features  FeatureSet        `json:"features"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.QueryID != "create" || body.Variables["tweet_text"] != "hello" || len(body.Features) != 1 {
+		t.Fatalf("unexpected mutation body %s", raw)
+	}
+}
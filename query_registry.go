@@ -0,0 +1,292 @@
+package helicon
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OperationInfo is a GraphQL operation as the web client knows it.
+type OperationInfo struct {
+	QueryID       string `json:"query_id"`
+	OperationName string `json:"operation_name"`
+	// query or mutation.
+	OperationType string `json:"operation_type"`
+	// names of features and field toggles web client sends with this operation.
+	FeatureSwitches []string `json:"feature_switches,omitempty"`
+	FieldToggles    []string `json:"field_toggles,omitempty"`
+}
+
+// QueryRegistry maps operation names (TweetDetail, UserByScreenName...) to query IDs, which X rotates whenever it
+// deploys a new frontend. IDs are parsed out of main.js and its lazily-loaded chunks, see [QueryRegistry.Refresh].
+//
+// Zero value is ready to use.
+type QueryRegistry struct {
+	// picks which chunks referenced from main.js are downloaded and parsed, there are hundreds of them and most are UI.
+	// default is [DefaultChunkFilter].
+	ChunkFilter func(name string) bool
+
+	mu          sync.RWMutex
+	operations  map[string]OperationInfo
+	features    FeatureSet
+	learned     map[string]FeatureSet
+	refreshedAt time.Time
+	failedAt    time.Time
+	// refresh in progress, concurrent callers wait for it instead of downloading everything again.
+	refreshing *registryRefresh
+}
+
+type registryRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshBackoff is how long a failed refresh is not retried, so a broken discovery does not cost a request every call.
+const refreshBackoff = 5 * time.Minute
+
+// DefaultQueryRegistry is used by [TweetDetailRequest.GetURL] and by every [Helicon] that has no [Helicon.QueryRegistry].
+var DefaultQueryRegistry = &QueryRegistry{}
+
+// DefaultChunkFilter picks chunks that hold API definitions.
+func DefaultChunkFilter(name string) bool {
+	return name == "api" || strings.HasPrefix(name, "endpoints.") || strings.HasPrefix(name, "ondemand.")
+}
+
+var (
+	operationPattern = regexp.MustCompile(`queryId\s*:\s*"([\w-]+)"\s*,\s*operationName\s*:\s*"(\w+)"\s*,\s*operationType\s*:\s*"(\w+)"` +
+		`(?:\s*,\s*metadata\s*:\s*\{\s*featureSwitches\s*:\s*\[([^\]]*)\]\s*,\s*fieldToggles\s*:\s*\[([^\]]*)\])?`)
+	// webpack chunk map inside main.js, `"endpoints.Tweet":"5b7a2c1",api:"0c3d9e2"`, keys are quoted only when they have to be.
+	chunkMapPattern = regexp.MustCompile(`(?:"([\w.~-]+)"|\b(\w+))\s*:\s*"([0-9a-f]{6,10})"`)
+)
+
+// ParseOperations returns every operation definition found in a web client script.
+func ParseOperations(script []byte) []OperationInfo {
+	matches := operationPattern.FindAllSubmatch(script, -1)
+	operations := make([]OperationInfo, 0, len(matches))
+	for _, m := range matches {
+		operations = append(operations, OperationInfo{
+			QueryID:         string(m[1]),
+			OperationName:   string(m[2]),
+			OperationType:   string(m[3]),
+			FeatureSwitches: splitQuotedList(string(m[4])),
+			FieldToggles:    splitQuotedList(string(m[5])),
+		})
+	}
+	return operations
+}
+
+// chunkUrls returns urls of lazily-loaded chunks referenced from main.js, chunks live next to main.js as `<name>.<hash>a.js`.
+func chunkUrls(mainUrl string, mainScript []byte, filter func(string) bool) []string {
+	base := mainUrl[:strings.LastIndex(mainUrl, "/")+1]
+	seen := map[string]bool{}
+	var urls []string
+	for _, m := range chunkMapPattern.FindAllSubmatch(mainScript, -1) {
+		name, hash := string(m[1])+string(m[2]), string(m[3])
+		if !filter(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		urls = append(urls, base+name+"."+hash+"a.js")
+	}
+	return urls
+}
+
+// QueryID returns query ID of operation if registry knows it.
+func (r *QueryRegistry) QueryID(operation string) (string, bool) {
+	info, ok := r.Operation(operation)
+	return info.QueryID, ok
+}
+
+// Operation returns everything registry knows about operation.
+func (r *QueryRegistry) Operation(operation string) (OperationInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.operations[operation]
+	return info, ok
+}
+
+// Operations returns a copy of every known operation, keyed by operation name.
+func (r *QueryRegistry) Operations() map[string]OperationInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.operations)
+}
+
+// Features returns feature switch config of web client, see [ParseInitialStateFeatures].
+func (r *QueryRegistry) Features() FeatureSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.features)
+}
+
+// OperationFeatures returns every feature switch web client sends with operation, valued from [QueryRegistry.Features],
+// plus the ones learned with [QueryRegistry.LearnFeatures]. Switches missing from the config are false, unless they are
+// learned.
+func (r *QueryRegistry) OperationFeatures(operation string) FeatureSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	// learned switches go under the config, a switch X asked for is valued from the config once it knows it.
+	features := r.learned[operation].Merge()
+	for name := range features {
+		if enabled, ok := r.features[name]; ok {
+			features[name] = enabled
+		}
+	}
+	for _, name := range r.operations[operation].FeatureSwitches {
+		if _, learned := features[name]; !learned {
+			features[name] = r.features[name]
+		}
+	}
+	return features
+}
+
+// LearnFeatures adds features to every future [QueryRegistry.OperationFeatures] of operation, for switches config of
+// web client does not know. learned features survive refreshes, they are what X asked for after discovery, see
+// [Helicon.OnHeal].
+func (r *QueryRegistry) LearnFeatures(operation string, features FeatureSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.learned == nil {
+		r.learned = map[string]FeatureSet{}
+	}
+	r.learned[operation] = r.learned[operation].Merge(features)
+}
+
+// Set adds or replaces an operation, until the next refresh rediscovers it.
+func (r *QueryRegistry) Set(info OperationInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.operations == nil {
+		r.operations = map[string]OperationInfo{}
+	}
+	r.operations[info.OperationName] = info
+}
+
+// Stale is true if registry was never refreshed, or refreshed longer than ttl ago.
+// it is not stale for a few minutes after a failed refresh.
+func (r *QueryRegistry) Stale(ttl time.Duration) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.failedAt.IsZero() && time.Since(r.failedAt) < refreshBackoff {
+		return false
+	}
+	return r.refreshedAt.IsZero() || time.Since(r.refreshedAt) > ttl
+}
+
+// Refresh re-discovers operations from the current main.js and chunks. Results are cached on disk next to the
+// bootstrap cache with the same key, so nothing but the login page is downloaded until X deploys a new frontend.
+// Concurrent calls share one refresh and its result.
+func (r *QueryRegistry) Refresh(h *Helicon) error {
+	r.mu.Lock()
+	if call := r.refreshing; call != nil {
+		r.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &registryRefresh{done: make(chan struct{})}
+	r.refreshing = call
+	r.mu.Unlock()
+
+	call.err = r.refresh(h)
+	r.mu.Lock()
+	if call.err != nil {
+		r.failedAt = time.Now()
+	}
+	r.refreshing = nil
+	r.mu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+func (r *QueryRegistry) refresh(h *Helicon) error {
+	page, err := h.fetchLoginPage()
+	if err != nil {
+		return err
+	}
+	mainUrl, err := findMainJavascriptUrl(page.html)
+	if err != nil {
+		return err
+	}
+	features, err := ParseInitialStateFeatures(page.html)
+	if err != nil {
+		// query IDs are still useful without feature values, switches will default to false.
+		slog.Warn("failed to discover feature switch config", "error", err)
+	}
+	mainHash := mainJavascriptHash(mainUrl)
+	cache := h.bootstrapCache()
+	var operations map[string]OperationInfo
+	if !cache.load("operations-"+mainHash, &operations) || len(operations) == 0 {
+		if operations, err = r.discover(h, mainUrl); err != nil {
+			return err
+		}
+		cache.save("operations-"+mainHash, operations)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.operations == nil {
+		r.operations = map[string]OperationInfo{}
+	}
+	maps.Copy(r.operations, operations)
+	if len(features) > 0 {
+		r.features = features
+	}
+	r.refreshedAt = time.Now()
+	r.failedAt = time.Time{}
+	return nil
+}
+
+func (r *QueryRegistry) discover(h *Helicon, mainUrl string) (map[string]OperationInfo, error) {
+	mainScript, err := h.fetchStatic(mainUrl)
+	if err != nil {
+		return nil, err
+	}
+	operations := map[string]OperationInfo{}
+	for _, info := range ParseOperations(mainScript) {
+		operations[info.OperationName] = info
+	}
+	filter := r.ChunkFilter
+	if filter == nil {
+		filter = DefaultChunkFilter
+	}
+	for _, chunkUrl := range chunkUrls(mainUrl, mainScript, filter) {
+		chunk, err := h.fetchStatic(chunkUrl)
+		if err != nil {
+			// chunk names are guesswork, one missing chunk should not lose the rest.
+			slog.Warn("failed to fetch web client chunk", "url", chunkUrl, "error", err)
+			continue
+		}
+		for _, info := range ParseOperations(chunk) {
+			operations[info.OperationName] = info
+		}
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("no GraphQL operations found in %s or its chunks", mainUrl)
+	}
+	return operations, nil
+}
+
+func (h *Helicon) queryRegistry() *QueryRegistry {
+	if h.QueryRegistry != nil {
+		return h.QueryRegistry
+	}
+	return DefaultQueryRegistry
+}
+
+// QueryID returns the current query ID of operation, refreshing the registry first if it is stale.
+// fallback is returned when operation can't be discovered, so a broken discovery never breaks a working request.
+func (h *Helicon) QueryID(operation string, fallback string) string {
+	registry := h.queryRegistry()
+	if registry.Stale(h.bootstrapCache().TTL) {
+		if err := registry.Refresh(h); err != nil {
+			slog.Warn("failed to refresh GraphQL query IDs, using known ones", "error", err)
+		}
+	}
+	if queryID, ok := registry.QueryID(operation); ok {
+		return queryID
+	}
+	return fallback
+}
@@ -0,0 +1,70 @@
+package helicon
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseOperations(t *testing.T) {
+	t.Parallel()
+	script := []byte(`e.exports={queryId:"1RFzrZSUoVSgHzVK4MHWlg",operationName:"TweetDetail",operationType:"query",metadata:{featureSwitches:["rweb_video_screen_enabled"],fieldToggles:["withArticleRichContentState"]}}},` +
+		`71234:e=>{e.exports={queryId:"zFw-Xf5sK3mfqz8pAXcyLA",operationName:"CreateTweet",operationType:"mutation",metadata:{featureSwitches:[],fieldToggles:[]}}}`)
+	operations := ParseOperations(script)
+	if len(operations) != 2 {
+		t.Fatalf("expected 2 operations, got %+v", operations)
+	}
+	detail, create := operations[0], operations[1]
+	if detail.QueryID != "1RFzrZSUoVSgHzVK4MHWlg" || detail.OperationName != "TweetDetail" || detail.OperationType != "query" {
+		t.Errorf("unexpected operation %+v", detail)
+	}
+	if !slices.Equal(detail.FeatureSwitches, []string{"rweb_video_screen_enabled"}) || !slices.Equal(detail.FieldToggles, []string{"withArticleRichContentState"}) {
+		t.Errorf("unexpected metadata %+v", detail)
+	}
+	if create.QueryID != "zFw-Xf5sK3mfqz8pAXcyLA" || create.OperationType != "mutation" || len(create.FeatureSwitches) != 0 {
+		t.Errorf("unexpected operation %+v", create)
+	}
+	mainScript := []byte(`n.u=e=>e+"."+{vendor:"f5e2a1b","endpoints.Tweet":"5b7a2c1",api:"0c3d9e2","bundle.Settings":"aa11bb2"}[e]+"a.js"`)
+	urls := chunkUrls("https://abs.twimg.com/responsive-web/client-web/main.175fd69a.js", mainScript, DefaultChunkFilter)
+	if !slices.Equal(urls, []string{
+		"https://abs.twimg.com/responsive-web/client-web/endpoints.Tweet.5b7a2c1a.js",
+		"https://abs.twimg.com/responsive-web/client-web/api.0c3d9e2a.js",
+	}) {
+		t.Fatalf("unexpected chunk urls %v", urls)
+	}
+}
+
+func TestQueryRegistry_RefreshConcurrent(t *testing.T) {
+	var pages, scripts atomic.Int32
+	StubTransport(t, func(r *http.Request) (*http.Response, error) {
+		switch r.URL.Host + r.URL.Path {
+		case "x.com/i/flow/login/":
+			pages.Add(1)
+			// keep the refresh running until every caller is in.
+			time.Sleep(50 * time.Millisecond)
+			return StubResponse(r, http.StatusOK, `<script src="https://abs.twimg.com/responsive-web/client-web/main.cafe1234.js"></script>`), nil
+		case "abs.twimg.com/responsive-web/client-web/main.cafe1234.js":
+			scripts.Add(1)
+			return StubResponse(r, http.StatusOK, `e.exports={queryId:"detail",operationName:"TweetDetail",operationType:"query"}`), nil
+		}
+		return StubResponse(r, http.StatusNotFound, ""), nil
+	})
+	h := &Helicon{QueryRegistry: &QueryRegistry{}, BootstrapCache: &BootstrapCache{Disabled: true}}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if queryID := h.QueryID("TweetDetail", "fallback"); queryID != "detail" {
+				t.Errorf("unexpected query ID %q", queryID)
+			}
+		}()
+	}
+	wg.Wait()
+	if pages.Load() != 1 || scripts.Load() != 1 {
+		t.Errorf("concurrent callers must share one refresh, login page fetched %d times, main.js %d times", pages.Load(), scripts.Load())
+	}
+}
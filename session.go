@@ -0,0 +1,378 @@
+package helicon
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Session is everything helicon needs to make authenticated requests without logging in again.
+// It is what goes inside an encrypted session bundle, see [Helicon.ExportSession] and [Helicon.ImportSession].
+type Session struct {
+	Username  string `json:"username"`
+	UserID    string `json:"user_id,omitempty"`
+	UserAgent string `json:"user_agent"`
+	// cookies are kept in raw Set-Cookie format, same as keyring, they are parsed again on import.
+	CSRFToken   string `json:"csrf_token"`
+	AuthToken   string `json:"auth_token"`
+	BearerToken string `json:"bearer_token"`
+	// free-form, whatever you want to carry along with the session (machine name, who exported it, etc.)
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+var (
+	// ErrSessionBundleTampered is returned from [Helicon.ImportSession] when the bundle fails integrity check.
+	// wrong passphrase / identity also ends up here, there is no way to tell these apart with AEAD.
+	ErrSessionBundleTampered = errors.New("session bundle is corrupted, tampered with, or the key is wrong")
+	// ErrSessionBundleExpired is returned from [Helicon.ImportSession] when bundle or the auth_token inside it is expired.
+	ErrSessionBundleExpired = errors.New("session bundle is expired")
+	// ErrSessionWrongRecipient is returned from [Helicon.ImportSession] when bundle is encrypted to another public key
+	// than the one of [ImportSessionOptions.Identity].
+	ErrSessionWrongRecipient = errors.New("session bundle is encrypted to another recipient")
+)
+
+const (
+	sessionBundleMagic      = "helicon-session/v1"
+	sessionKDFPassphrase    = "pbkdf2-sha256"
+	sessionKDFX25519        = "x25519-hkdf-sha256"
+	sessionPBKDF2Iterations = 600_000
+	// the header can only be authenticated after the key is derived, a bundle asking for billions of iterations would
+	// hang the import before tampering is ever detected.
+	maxSessionPBKDF2Iterations = 10 * sessionPBKDF2Iterations
+)
+
+// sessionBundleHeader is the first line of the bundle. it is not encrypted, but it is authenticated as GCM additional data,
+// so changing anything in it (say, pushing expires_at forward) is detected as tampering.
+type sessionBundleHeader struct {
+	KDF        string    `json:"kdf"`
+	Salt       []byte    `json:"salt,omitempty"`
+	Iterations int       `json:"iterations,omitempty"`
+	Ephemeral  []byte    `json:"ephemeral,omitempty"`
+	Recipient  []byte    `json:"recipient,omitempty"`
+	Nonce      []byte    `json:"nonce"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type ExportSessionOptions struct {
+	// either Passphrase or Recipient must be set, not both.
+	Passphrase string
+	// X25519 public key of the machine that will import this bundle, see [GenerateSessionIdentity].
+	Recipient *ecdh.PublicKey
+	// how long the bundle is importable. zero means until the auth_token cookie expires,
+	// and if that is unknown too, 24 hours.
+	TTL      time.Duration
+	Metadata map[string]string
+}
+
+type ImportSessionOptions struct {
+	// must match whatever was used on export.
+	Passphrase string
+	Identity   *ecdh.PrivateKey
+}
+
+// GenerateSessionIdentity creates a new X25519 key pair for public-key session bundles.
+// Keep the private key on the importing machine, give the public key ([ecdh.PrivateKey.PublicKey]) to the exporting side.
+// See [EncodeSessionKey] / [ParseSessionRecipient] / [ParseSessionIdentity] for moving them around as text.
+func GenerateSessionIdentity() (*ecdh.PrivateKey, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate X25519 key: %w", err)
+	}
+	return key, nil
+}
+
+// EncodeSessionKey encodes a public or private session key as base64, suitable for env variables and CI secrets.
+func EncodeSessionKey(key interface{ Bytes() []byte }) string {
+	return base64.StdEncoding.EncodeToString(key.Bytes())
+}
+
+// ParseSessionRecipient parses a public key encoded with [EncodeSessionKey].
+func ParseSessionRecipient(encoded string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode recipient: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 public key: %w", err)
+	}
+	return key, nil
+}
+
+// ParseSessionIdentity parses a private key encoded with [EncodeSessionKey].
+func ParseSessionIdentity(encoded string) (*ecdh.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode identity: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 private key: %w", err)
+	}
+	return key, nil
+}
+
+// Session returns a snapshot of current session. Tokens must be present, either from [Helicon.Login] or [Helicon.LoadTokensFromKeyring].
+func (h *Helicon) Session() (*Session, error) {
+	if h.Cookies.AuthToken.Raw == "" || h.Cookies.CSRFToken.Raw == "" {
+		return nil, fmt.Errorf("no session to export, login or load tokens first")
+	}
+	return &Session{
+		Username:    h.Credentials.Username,
+		UserID:      h.UserID,
+		UserAgent:   h.UserAgent,
+		CSRFToken:   h.Cookies.CSRFToken.Raw,
+		AuthToken:   h.Cookies.AuthToken.Raw,
+		BearerToken: h.Cookies.BearerToken,
+		CreatedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// ExportSession writes current session as a single encrypted bundle into w.
+//
+// Bundle is AES-256-GCM encrypted, key is derived either from a passphrase (PBKDF2-SHA256)
+// or from an X25519 exchange with the recipient public key. Log in once on a workstation, export, and
+// import it on CI runners with [Helicon.ImportSession] instead of logging in from every one of them.
+func (h *Helicon) ExportSession(w io.Writer, opts ExportSessionOptions) error {
+	session, err := h.Session()
+	if err != nil {
+		return err
+	}
+	session.Metadata = opts.Metadata
+	if err = h.Cookies.AuthToken.Parse(); err != nil {
+		return fmt.Errorf("failed to parse auth_token cookie: %w", err)
+	}
+	switch {
+	case opts.TTL > 0:
+		session.ExpiresAt = session.CreatedAt.Add(opts.TTL)
+	case !h.Cookies.AuthToken.Expires.IsZero():
+		session.ExpiresAt = h.Cookies.AuthToken.Expires.UTC()
+	default:
+		session.ExpiresAt = session.CreatedAt.Add(24 * time.Hour)
+	}
+	header := sessionBundleHeader{ExpiresAt: session.ExpiresAt}
+	var key []byte
+	switch {
+	case opts.Passphrase != "" && opts.Recipient != nil:
+		return fmt.Errorf("both passphrase and recipient are set, pick one")
+	case opts.Passphrase != "":
+		header.KDF = sessionKDFPassphrase
+		header.Iterations = sessionPBKDF2Iterations
+		header.Salt = make([]byte, 16)
+		if _, err = rand.Read(header.Salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+		key, err = pbkdf2.Key(sha256.New, opts.Passphrase, header.Salt, header.Iterations, 32)
+	case opts.Recipient != nil:
+		var ephemeral *ecdh.PrivateKey
+		if ephemeral, err = GenerateSessionIdentity(); err != nil {
+			return err
+		}
+		header.KDF = sessionKDFX25519
+		header.Ephemeral = ephemeral.PublicKey().Bytes()
+		header.Recipient = opts.Recipient.Bytes()
+		key, err = x25519SessionKey(ephemeral, opts.Recipient, header.Ephemeral)
+	default:
+		return fmt.Errorf("either passphrase or recipient is required to export a session")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to derive bundle key: %w", err)
+	}
+	aead, err := newSessionAEAD(key)
+	if err != nil {
+		return err
+	}
+	header.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(header.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle header: %w", err)
+	}
+	headerLine := base64.StdEncoding.EncodeToString(headerJSON)
+	ciphertext := aead.Seal(nil, header.Nonce, plaintext, []byte(headerLine))
+	_, err = fmt.Fprintf(w, "%s\n%s\n%s\n", sessionBundleMagic, headerLine, base64.StdEncoding.EncodeToString(ciphertext))
+	if err != nil {
+		return fmt.Errorf("failed to write session bundle: %w", err)
+	}
+	return nil
+}
+
+// ExportSessionFile is [Helicon.ExportSession] into a file only readable by the current user.
+func (h *Helicon) ExportSessionFile(path string, opts ExportSessionOptions) error {
+	var buf bytes.Buffer
+	if err := h.ExportSession(&buf, opts); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write session bundle to %s: %w", path, err)
+	}
+	return nil
+}
+
+// ImportSession reads a bundle written by [Helicon.ExportSession], verifies and decrypts it, and loads
+// the session into h. Returns [ErrSessionBundleTampered] if the bundle does not authenticate and
+// [ErrSessionBundleExpired] if bundle (or the auth_token inside it) is expired.
+//
+// Imported tokens are not saved anywhere, call [Helicon.SaveTokensToKeyring] if you want them to stick around.
+func (h *Helicon) ImportSession(r io.Reader, opts ImportSessionOptions) (*Session, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session bundle: %w", err)
+	}
+	if len(lines) != 3 || lines[0] != sessionBundleMagic {
+		return nil, fmt.Errorf("not a helicon session bundle: %w", ErrSessionBundleTampered)
+	}
+	headerJSON, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bundle header: %w", ErrSessionBundleTampered)
+	}
+	var header sessionBundleHeader
+	if err = json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle header: %w", ErrSessionBundleTampered)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bundle payload: %w", ErrSessionBundleTampered)
+	}
+	var key []byte
+	switch header.KDF {
+	case sessionKDFPassphrase:
+		if opts.Passphrase == "" {
+			return nil, fmt.Errorf("bundle is passphrase protected, but no passphrase is given")
+		}
+		if header.Iterations <= 0 || header.Iterations > maxSessionPBKDF2Iterations || len(header.Salt) == 0 {
+			return nil, fmt.Errorf("invalid passphrase parameters in header: %w", ErrSessionBundleTampered)
+		}
+		key, err = pbkdf2.Key(sha256.New, opts.Passphrase, header.Salt, header.Iterations, 32)
+	case sessionKDFX25519:
+		if opts.Identity == nil {
+			return nil, fmt.Errorf("bundle is encrypted to a public key, but no identity is given")
+		}
+		if !bytes.Equal(opts.Identity.PublicKey().Bytes(), header.Recipient) {
+			return nil, ErrSessionWrongRecipient
+		}
+		var ephemeral *ecdh.PublicKey
+		if ephemeral, err = ecdh.X25519().NewPublicKey(header.Ephemeral); err != nil {
+			return nil, fmt.Errorf("invalid ephemeral key in header: %w", ErrSessionBundleTampered)
+		}
+		key, err = x25519SessionKey(opts.Identity, ephemeral, header.Ephemeral)
+	default:
+		return nil, fmt.Errorf("unknown bundle key derivation %q: %w", header.KDF, ErrSessionBundleTampered)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive bundle key: %w", err)
+	}
+	aead, err := newSessionAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(header.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce in header: %w", ErrSessionBundleTampered)
+	}
+	plaintext, err := aead.Open(nil, header.Nonce, ciphertext, []byte(lines[1]))
+	if err != nil {
+		return nil, ErrSessionBundleTampered
+	}
+	var session Session
+	if err = json.Unmarshal(plaintext, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if !session.ExpiresAt.Equal(header.ExpiresAt) {
+		return nil, fmt.Errorf("header and payload expiry mismatch: %w", ErrSessionBundleTampered)
+	}
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
+		return nil, fmt.Errorf("bundle expired at %s: %w", session.ExpiresAt, ErrSessionBundleExpired)
+	}
+	var cookies TwitterCookies
+	cookies.CSRFToken.Raw = session.CSRFToken
+	if err = cookies.CSRFToken.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse CSRFToken cookie: %w", err)
+	}
+	cookies.AuthToken.Raw = session.AuthToken
+	if err = cookies.AuthToken.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse auth_token cookie: %w", err)
+	}
+	if !cookies.AuthToken.Expires.IsZero() && now.After(cookies.AuthToken.Expires) {
+		return nil, fmt.Errorf("auth_token expired at %s: %w", cookies.AuthToken.Expires, ErrSessionBundleExpired)
+	}
+	cookies.BearerToken = session.BearerToken
+	h.Cookies = cookies
+	h.Credentials.Username = session.Username
+	h.UserID = session.UserID
+	h.UserAgent = session.UserAgent
+	return &session, nil
+}
+
+// ImportSessionFile is [Helicon.ImportSession] from a file.
+func (h *Helicon) ImportSessionFile(path string, opts ImportSessionOptions) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session bundle %s: %w", path, err)
+	}
+	defer f.Close()
+	return h.ImportSession(f, opts)
+}
+
+func x25519SessionKey(private *ecdh.PrivateKey, public *ecdh.PublicKey, ephemeral []byte) ([]byte, error) {
+	shared, err := private.ECDH(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed X25519 exchange: %w", err)
+	}
+	// ephemeral is same on both sides, recipient is whichever public key belongs to the identity.
+	salt := append(append([]byte{}, ephemeral...), recipientOf(private, public, ephemeral)...)
+	key, err := hkdf.Key(sha256.New, shared, salt, sessionBundleMagic, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand shared secret: %w", err)
+	}
+	return key, nil
+}
+
+// recipientOf returns the recipient public key in the exchange, which is the side that is not the ephemeral one.
+func recipientOf(private *ecdh.PrivateKey, public *ecdh.PublicKey, ephemeral []byte) []byte {
+	if bytes.Equal(private.PublicKey().Bytes(), ephemeral) {
+		return public.Bytes()
+	}
+	return private.PublicKey().Bytes()
+}
+
+func newSessionAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,149 @@
+package helicon_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/caner-cetin/helicon"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSessionClient() *helicon.Helicon {
+	expires := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC1123)
+	var client helicon.Helicon
+	client.SetLoginCredentials("someone", "")
+	client.SetDefaultUserAgent(nil)
+	client.UserID = "1234"
+	client.Cookies.CSRFToken.Raw = fmt.Sprintf("ct0=csrf; Expires=%s; Path=/; Domain=.x.com; Secure", expires)
+	client.Cookies.AuthToken.Raw = fmt.Sprintf("auth_token=auth; Expires=%s; Path=/; Domain=.x.com; Secure; HttpOnly", expires)
+	client.Cookies.BearerToken = "Bearer anonymous"
+	return &client
+}
+
+func TestHelicon_ExportImportSession(t *testing.T) {
+	t.Parallel()
+	identity, err := helicon.GenerateSessionIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		export helicon.ExportSessionOptions
+		open   helicon.ImportSessionOptions
+	}{
+		{"passphrase", helicon.ExportSessionOptions{Passphrase: "hunter2"}, helicon.ImportSessionOptions{Passphrase: "hunter2"}},
+		{"recipient", helicon.ExportSessionOptions{Recipient: identity.PublicKey()}, helicon.ImportSessionOptions{Identity: identity}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var bundle bytes.Buffer
+			tc.export.Metadata = map[string]string{"exported_by": "workstation"}
+			if err := newSessionClient().ExportSession(&bundle, tc.export); err != nil {
+				t.Fatal(err)
+			}
+			var imported helicon.Helicon
+			session, err := imported.ImportSession(bytes.NewReader(bundle.Bytes()), tc.open)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imported.Cookies.AuthToken.Value != "auth" || imported.Cookies.CSRFToken.Value != "csrf" {
+				t.Fatalf("cookies not restored: %+v", imported.Cookies)
+			}
+			if imported.UserID != "1234" || imported.Credentials.Username != "someone" || imported.UserAgent == "" {
+				t.Fatalf("identity not restored: %+v", imported)
+			}
+			if session.Metadata["exported_by"] != "workstation" {
+				t.Fatalf("metadata not restored: %v", session.Metadata)
+			}
+		})
+	}
+}
+
+func TestHelicon_ImportSessionTampered(t *testing.T) {
+	t.Parallel()
+	var bundle bytes.Buffer
+	if err := newSessionClient().ExportSession(&bundle, helicon.ExportSessionOptions{Passphrase: "hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(bundle.String(), "\n")
+	payload := []byte(lines[2])
+	payload[10] ^= 'A' ^ 'B'
+	lines[2] = string(payload)
+	var imported helicon.Helicon
+	_, err := imported.ImportSession(strings.NewReader(strings.Join(lines, "\n")), helicon.ImportSessionOptions{Passphrase: "hunter2"})
+	if !errors.Is(err, helicon.ErrSessionBundleTampered) {
+		t.Fatalf("expected tampered error, got %v", err)
+	}
+	_, err = imported.ImportSession(bytes.NewReader(bundle.Bytes()), helicon.ImportSessionOptions{Passphrase: "wrong"})
+	if !errors.Is(err, helicon.ErrSessionBundleTampered) {
+		t.Fatalf("expected tampered error with wrong passphrase, got %v", err)
+	}
+}
+
+func TestHelicon_ImportSessionWrongRecipient(t *testing.T) {
+	t.Parallel()
+	recipient, err := helicon.GenerateSessionIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := helicon.GenerateSessionIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle bytes.Buffer
+	if err := newSessionClient().ExportSession(&bundle, helicon.ExportSessionOptions{Recipient: recipient.PublicKey()}); err != nil {
+		t.Fatal(err)
+	}
+	var imported helicon.Helicon
+	_, err = imported.ImportSession(bytes.NewReader(bundle.Bytes()), helicon.ImportSessionOptions{Identity: other})
+	if !errors.Is(err, helicon.ErrSessionWrongRecipient) {
+		t.Fatalf("expected wrong recipient error, got %v", err)
+	}
+}
+
+func TestHelicon_ImportSessionExpired(t *testing.T) {
+	t.Parallel()
+	var bundle bytes.Buffer
+	if err := newSessionClient().ExportSession(&bundle, helicon.ExportSessionOptions{Passphrase: "hunter2", TTL: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	var imported helicon.Helicon
+	_, err := imported.ImportSession(bytes.NewReader(bundle.Bytes()), helicon.ImportSessionOptions{Passphrase: "hunter2"})
+	if !errors.Is(err, helicon.ErrSessionBundleExpired) {
+		t.Fatalf("expected expired error, got %v", err)
+	}
+}
+
+func TestHelicon_ImportSessionIterations(t *testing.T) {
+	t.Parallel()
+	var bundle bytes.Buffer
+	if err := newSessionClient().ExportSession(&bundle, helicon.ExportSessionOptions{Passphrase: "hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(bundle.String(), "\n")
+	headerJSON, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header map[string]any
+	if err = json.Unmarshal(headerJSON, &header); err != nil {
+		t.Fatal(err)
+	}
+	header["iterations"] = math.MaxInt32
+	if headerJSON, err = json.Marshal(header); err != nil {
+		t.Fatal(err)
+	}
+	lines[1] = base64.StdEncoding.EncodeToString(headerJSON)
+	var imported helicon.Helicon
+	_, err = imported.ImportSession(strings.NewReader(strings.Join(lines, "\n")), helicon.ImportSessionOptions{Passphrase: "hunter2"})
+	if !errors.Is(err, helicon.ErrSessionBundleTampered) {
+		t.Fatalf("expected tampered error, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+// Package snowflake decodes IDs of tweets, users, media and everything else on X, and builds IDs for time ranges.
+//
+// An ID is 64 bits, from the top:
+//
+//	1 bit   unused, always zero
+//	41 bits milliseconds since Epoch
+//	5 bits  datacenter
+//	5 bits  worker in the datacenter
+//	12 bits sequence, IDs made by the same worker in the same millisecond
+//
+// IDs older than November 2010, and users older than 2013, are not snowflakes, they are sequential and their Time is
+// meaningless.
+package snowflake
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Epoch is the start of snowflake time, 2010-11-04T01:42:54.657Z, in unix milliseconds.
+const Epoch int64 = 1288834974657
+
+const (
+	sequenceBits   = 12
+	workerBits     = 5
+	datacenterBits = 5
+	timeShift      = sequenceBits + workerBits + datacenterBits
+
+	maxSequence   = 1<<sequenceBits - 1
+	maxWorker     = 1<<workerBits - 1
+	maxDatacenter = 1<<datacenterBits - 1
+)
+
+// ID is a snowflake ID.
+type ID int64
+
+// Parse parses a decimal ID, id_str or rest_id.
+func Parse(id string) (ID, error) {
+	parsed, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse snowflake %q: %w", id, err)
+	}
+	if parsed < 0 {
+		return 0, fmt.Errorf("failed to parse snowflake %q: negative", id)
+	}
+	return ID(parsed), nil
+}
+
+// New builds an ID, datacenter, worker and sequence are cut to their bits.
+func New(t time.Time, datacenter, worker, sequence int) ID {
+	ms := max(t.UnixMilli()-Epoch, 0)
+	return ID(ms<<timeShift |
+		int64(datacenter&maxDatacenter)<<(sequenceBits+workerBits) |
+		int64(worker&maxWorker)<<sequenceBits |
+		int64(sequence&maxSequence))
+}
+
+// FromTime returns the smallest ID made at t. with [Range] it is what search operators since_id and max_id take.
+func FromTime(t time.Time) ID {
+	return New(t, 0, 0, 0)
+}
+
+// Range returns the smallest ID made at since and the largest one made before until, every ID made in between is in
+// [minID, maxID].
+//
+//	minID, maxID := snowflake.Range(since, until)
+//	query := fmt.Sprintf("from:golang since_id:%s max_id:%s", minID, maxID)
+func Range(since, until time.Time) (minID, maxID ID) {
+	return FromTime(since), FromTime(until) - 1
+}
+
+// Time is when the ID was made, milliseconds precise.
+func (id ID) Time() time.Time {
+	return time.UnixMilli(int64(id)>>timeShift + Epoch)
+}
+
+// Datacenter is the datacenter of the worker that made the ID.
+func (id ID) Datacenter() int {
+	return int(id>>(sequenceBits+workerBits)) & maxDatacenter
+}
+
+// Worker is the worker that made the ID, in its datacenter.
+func (id ID) Worker() int {
+	return int(id>>sequenceBits) & maxWorker
+}
+
+// Sequence tells IDs made by the same worker in the same millisecond apart.
+func (id ID) Sequence() int {
+	return int(id) & maxSequence
+}
+
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
@@ -0,0 +1,47 @@
+package snowflake_test
+
+import (
+	"github.com/caner-cetin/helicon/snowflake"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	id, err := snowflake.Parse("1800000000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := id.Time(), time.Date(2024, 6, 10, 3, 0, 17, 39_000_000, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got.UTC())
+	}
+	if id.Datacenter() != 26 || id.Worker() != 0 || id.Sequence() != 1 || id.String() != "1800000000000000001" {
+		t.Errorf("unexpected parts of %s: %d %d %d", id, id.Datacenter(), id.Worker(), id.Sequence())
+	}
+	for _, invalid := range []string{"", "abc", "-1", "99999999999999999999"} {
+		if _, err := snowflake.Parse(invalid); err == nil {
+			t.Errorf("%q must not parse", invalid)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2024, 6, 1, 11, 0, 0, 123_000_000, time.UTC)
+	id := snowflake.New(at, 11, 22, 333)
+	if !id.Time().Equal(at) || id.Datacenter() != 11 || id.Worker() != 22 || id.Sequence() != 333 {
+		t.Errorf("parts of %s do not round trip: %v %d %d %d", id, id.Time(), id.Datacenter(), id.Worker(), id.Sequence())
+	}
+	if snowflake.New(at, 32, 32, 4096) != snowflake.FromTime(at) {
+		t.Error("parts must be cut to their bits")
+	}
+
+	since, until := at, at.Add(time.Hour)
+	minID, maxID := snowflake.Range(since, until)
+	if minID != snowflake.FromTime(since) || maxID.Time().Before(since) || !maxID.Time().Before(until) || maxID+1 != snowflake.FromTime(until) {
+		t.Errorf("unexpected range [%s, %s]", minID, maxID)
+	}
+	if id < minID || id > maxID {
+		t.Errorf("%s must be in [%s, %s]", id, minID, maxID)
+	}
+}
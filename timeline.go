@@ -0,0 +1,317 @@
+package helicon
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"slices"
+)
+
+// InstructionType is `type` of a timeline instruction. Every timeline of X, home, profiles, search, conversations, is
+// a list of these, applied in order by the client.
+type InstructionType string
+
+const (
+	// InstructionAddEntries adds [TimelineInstruction.Entries], replacing the ones with the same ID.
+	InstructionAddEntries InstructionType = "TimelineAddEntries"
+	// InstructionReplaceEntry replaces [TimelineInstruction.EntryIdToReplace] with [TimelineInstruction.Entry], cursors
+	// of later pages come like this.
+	InstructionReplaceEntry InstructionType = "TimelineReplaceEntry"
+	// InstructionAddToModule adds [TimelineInstruction.ModuleItems] to the module with [TimelineInstruction.ModuleEntryId].
+	InstructionAddToModule InstructionType = "TimelineAddToModule"
+	// InstructionPinEntry pins [TimelineInstruction.Entry] on top, pinned tweet of a profile.
+	InstructionPinEntry InstructionType = "TimelinePinEntry"
+	// InstructionClearCache drops every entry client has.
+	InstructionClearCache InstructionType = "TimelineClearCache"
+	// InstructionTerminateTimeline means there is nothing more towards [TimelineInstruction.Direction].
+	InstructionTerminateTimeline InstructionType = "TimelineTerminateTimeline"
+	// InstructionShowAlert is the "new posts" pill.
+	InstructionShowAlert InstructionType = "TimelineShowAlert"
+	// InstructionShowCover is a full screen prompt over the timeline.
+	InstructionShowCover InstructionType = "TimelineShowCover"
+	// InstructionClearEntriesUnreadState and InstructionMarkEntriesUnread are about unread state of notifications.
+	InstructionClearEntriesUnreadState InstructionType = "TimelineClearEntriesUnreadState"
+	InstructionMarkEntriesUnread       InstructionType = "TimelineMarkEntriesUnreadGreaterThanSortIndex"
+)
+
+// TimelineInstruction is one instruction of a timeline, which fields are set depends on [TimelineInstruction.Type].
+type TimelineInstruction struct {
+	Type InstructionType `json:"type"`
+	// TimelineAddEntries.
+	Entries []TimelineEntry `json:"entries,omitempty"`
+	// TimelineReplaceEntry and TimelinePinEntry.
+	Entry            *TimelineEntry `json:"entry,omitempty"`
+	EntryIdToReplace string         `json:"entry_id_to_replace,omitempty"`
+	// TimelineAddToModule, items are appended to the module unless Prepend is set.
+	ModuleEntryId string               `json:"moduleEntryId,omitempty"`
+	ModuleItems   []TimelineModuleItem `json:"moduleItems,omitempty"`
+	Prepend       bool                 `json:"prepend,omitempty"`
+	// TimelineTerminateTimeline, Top, Bottom or TopAndBottom.
+	Direction string `json:"direction,omitempty"`
+	// TimelineShowAlert.
+	AlertType       string `json:"alertType,omitempty"`
+	DisplayLocation string `json:"displayLocation,omitempty"`
+	RichText        *struct {
+		Text string `json:"text"`
+	} `json:"richText,omitempty"`
+	TriggerDelayMs    int `json:"triggerDelayMs,omitempty"`
+	DisplayDurationMs int `json:"displayDurationMs,omitempty"`
+	// TimelineShowCover, left as is, there are too many kinds of them.
+	Cover json.RawMessage `json:"cover,omitempty"`
+	// TimelineMarkEntriesUnreadGreaterThanSortIndex.
+	SortIndex string `json:"sort_index,omitempty"`
+}
+
+// EntryType is `entryType` of a timeline entry.
+type EntryType string
+
+const (
+	// EntryItem is a single tweet, user, cursor, prompt...
+	EntryItem EntryType = "TimelineTimelineItem"
+	// EntryModule is a group of items shown together, a thread of replies or who to follow.
+	EntryModule EntryType = "TimelineTimelineModule"
+	// EntryCursor is where the next page starts.
+	EntryCursor EntryType = "TimelineTimelineCursor"
+)
+
+// TimelineEntry is one entry of a timeline. Entries are shown in descending order of SortIndex.
+type TimelineEntry struct {
+	EntryId   string               `json:"entryId"`
+	SortIndex string               `json:"sortIndex"`
+	Content   TimelineEntryContent `json:"content"`
+}
+
+// TimelineEntryContent is content of an entry, which fields are set depends on [TimelineEntryContent.EntryType].
+type TimelineEntryContent struct {
+	EntryType EntryType `json:"entryType"`
+	Typename  string    `json:"__typename"`
+	// TimelineTimelineItem.
+	ItemContent *TimelineItemContent `json:"itemContent,omitempty"`
+	// TimelineTimelineModule.
+	Items       []TimelineModuleItem `json:"items,omitempty"`
+	DisplayType string               `json:"displayType,omitempty"`
+	Header      *TimelineModuleText  `json:"header,omitempty"`
+	Footer      *TimelineModuleText  `json:"footer,omitempty"`
+	// TimelineTimelineCursor.
+	Value      string     `json:"value,omitempty"`
+	CursorType CursorType `json:"cursorType,omitempty"`
+	// analytics of the web client, left as is.
+	ClientEventInfo json.RawMessage `json:"clientEventInfo,omitempty"`
+}
+
+// TimelineModuleText is header or footer of a module, "Who to follow", "Show more"...
+type TimelineModuleText struct {
+	DisplayType string `json:"displayType,omitempty"`
+	Text        string `json:"text"`
+	LandingUrl  *struct {
+		Url string `json:"url"`
+	} `json:"landingUrl,omitempty"`
+}
+
+// TimelineModuleItem is one item of a module.
+type TimelineModuleItem struct {
+	EntryId string `json:"entryId"`
+	Item    struct {
+		ItemContent     TimelineItemContent `json:"itemContent"`
+		ClientEventInfo json.RawMessage     `json:"clientEventInfo,omitempty"`
+	} `json:"item"`
+}
+
+// ItemType is `itemType` of an item.
+type ItemType string
+
+const (
+	ItemTweet  ItemType = "TimelineTweet"
+	ItemUser   ItemType = "TimelineUser"
+	ItemCursor ItemType = "TimelineTimelineCursor"
+	ItemPrompt ItemType = "TimelinePrompt"
+)
+
+// TimelineItemContent is content of an item, which fields are set depends on [TimelineItemContent.ItemType].
+// tweets and users are as they are in the response, see [ExtractTweets] for flat ones.
+type TimelineItemContent struct {
+	ItemType ItemType `json:"itemType"`
+	Typename string   `json:"__typename"`
+	// TimelineTweet.
+	TweetResults     *TweetResults `json:"tweet_results,omitempty"`
+	TweetDisplayType string        `json:"tweetDisplayType,omitempty"`
+	SocialContext    *struct {
+		Type        string `json:"type"`
+		ContextType string `json:"contextType"`
+		Text        string `json:"text"`
+	} `json:"socialContext,omitempty"`
+	HasModeratedReplies bool `json:"hasModeratedReplies,omitempty"`
+	// set on ads.
+	PromotedMetadata json.RawMessage `json:"promotedMetadata,omitempty"`
+	// TimelineUser.
+	UserResults     *UserResults `json:"user_results,omitempty"`
+	UserDisplayType string       `json:"userDisplayType,omitempty"`
+	// TimelineTimelineCursor, cursors in modules are items.
+	Value      string     `json:"value,omitempty"`
+	CursorType CursorType `json:"cursorType,omitempty"`
+}
+
+// CursorType is `cursorType` of a cursor entry or item.
+type CursorType string
+
+const (
+	CursorTop    CursorType = "Top"
+	CursorBottom CursorType = "Bottom"
+	// CursorShowMore loads rest of a module, more replies of a reply thread.
+	CursorShowMore CursorType = "ShowMore"
+	// CursorShowMoreThreads loads more reply threads of a conversation.
+	CursorShowMoreThreads       CursorType = "ShowMoreThreads"
+	CursorShowMoreThreadsPrompt CursorType = "ShowMoreThreadsPrompt"
+)
+
+// Timeline is the instructions of a timeline response, see [ParseTimeline].
+type Timeline struct {
+	Instructions []TimelineInstruction `json:"instructions"`
+}
+
+// ErrNoTimeline is returned from [ParseTimeline] when the response has no instructions.
+var ErrNoTimeline = errors.New("response has no timeline instructions")
+
+// ParseTimeline decodes the timeline of any timeline response, it is the first `instructions` array in data, wherever
+// that is: `threaded_conversation_with_injections_v2` of TweetDetail, `user.result.timeline.timeline` of UserTweets,
+// `search_by_raw_query.search_timeline.timeline` of SearchTimeline...
+func ParseTimeline(data []byte) (*Timeline, error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(data), jsonv1.DefaultOptionsV1())
+	var timeline *Timeline
+	err := streamFind(dec, func(key string) bool {
+		return key == "instructions" && dec.PeekKind() == '['
+	}, func(string) error {
+		timeline = new(Timeline)
+		if err := jsonv2.UnmarshalDecode(dec, &timeline.Instructions); err != nil {
+			return err
+		}
+		return errStopStreaming
+	})
+	if err != nil && !errors.Is(err, errStopStreaming) {
+		return nil, fmt.Errorf("failed to parse timeline: %w", err)
+	}
+	if timeline == nil {
+		return nil, ErrNoTimeline
+	}
+	return timeline, nil
+}
+
+// GetTweetDetailTimeline is [Helicon.GetTweetDetails], as a [Timeline].
+func (h *Helicon) GetTweetDetailTimeline(request TweetDetailRequest, opts ...ExecuteOption) (*Timeline, error) {
+	op, err := request.Operation()
+	if err != nil {
+		return nil, err
+	}
+	var data json.RawMessage
+	if err := h.Execute(context.Background(), op, &data, opts...); err != nil {
+		return nil, err
+	}
+	return ParseTimeline(data)
+}
+
+// Entries applies the instructions in order, like the web client does, and returns the entries left, pinned entry first
+// and the rest in descending order of sort index.
+func (t *Timeline) Entries() []TimelineEntry {
+	var entries []TimelineEntry
+	var pinned *TimelineEntry
+	upsert := func(entry TimelineEntry) {
+		if i := slices.IndexFunc(entries, func(e TimelineEntry) bool { return e.EntryId == entry.EntryId }); i >= 0 {
+			entries[i] = entry
+			return
+		}
+		entries = append(entries, entry)
+	}
+	for _, instruction := range t.Instructions {
+		switch instruction.Type {
+		case InstructionClearCache:
+			entries, pinned = nil, nil
+		case InstructionAddEntries:
+			for _, entry := range instruction.Entries {
+				upsert(entry)
+			}
+		case InstructionReplaceEntry:
+			entries = slices.DeleteFunc(entries, func(e TimelineEntry) bool { return e.EntryId == instruction.EntryIdToReplace })
+			if instruction.Entry != nil {
+				upsert(*instruction.Entry)
+			}
+		case InstructionPinEntry:
+			pinned = instruction.Entry
+		case InstructionAddToModule:
+			for i, entry := range entries {
+				if entry.EntryId != instruction.ModuleEntryId {
+					continue
+				}
+				// items of the module are shared with the instruction otherwise.
+				items := slices.Clone(entry.Content.Items)
+				if instruction.Prepend {
+					items = append(slices.Clone(instruction.ModuleItems), items...)
+				} else {
+					items = append(items, instruction.ModuleItems...)
+				}
+				entries[i].Content.Items = items
+			}
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b TimelineEntry) int {
+		return compareSortIndex(b.SortIndex, a.SortIndex)
+	})
+	if pinned != nil {
+		entries = append([]TimelineEntry{*pinned}, entries...)
+	}
+	return entries
+}
+
+// Cursor returns value of the first cursor of cursorType, cursor entries and cursor items of modules both count.
+// Pass it as cursor of the next request to get the next page.
+func (t *Timeline) Cursor(cursorType CursorType) (string, bool) {
+	for _, entry := range t.Entries() {
+		if entry.Content.CursorType == cursorType {
+			return entry.Content.Value, true
+		}
+		if content := entry.Content.ItemContent; content != nil && content.CursorType == cursorType {
+			return content.Value, true
+		}
+		for _, item := range entry.Content.Items {
+			if item.Item.ItemContent.CursorType == cursorType {
+				return item.Item.ItemContent.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Terminated is true if the timeline says there is nothing more towards direction, Top or Bottom.
+func (t *Timeline) Terminated(direction string) bool {
+	return slices.ContainsFunc(t.Instructions, func(instruction TimelineInstruction) bool {
+		return instruction.Type == InstructionTerminateTimeline &&
+			(instruction.Direction == direction || instruction.Direction == "TopAndBottom")
+	})
+}
+
+// Items returns every item of the entry, itself if it is an item, items of it if it is a module, none for cursors.
+func (e TimelineEntry) Items() []TimelineItemContent {
+	if e.Content.ItemContent != nil {
+		return []TimelineItemContent{*e.Content.ItemContent}
+	}
+	items := make([]TimelineItemContent, 0, len(e.Content.Items))
+	for _, item := range e.Content.Items {
+		items = append(items, item.Item.ItemContent)
+	}
+	return items
+}
+
+// Promoted is true if the item is an ad.
+func (c TimelineItemContent) Promoted() bool {
+	return len(c.PromotedMetadata) > 0 && !bytes.Equal(c.PromotedMetadata, []byte("null"))
+}
+
+// compareSortIndex compares sort indexes, they are integers, too large for int64 sometimes.
+func compareSortIndex(a, b string) int {
+	return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+}
@@ -0,0 +1,96 @@
+package helicon_test
+
+import (
+	"errors"
+	"github.com/caner-cetin/helicon"
+	"os"
+	"testing"
+)
+
+func TestParseTimeline(t *testing.T) {
+	t.Parallel()
+	data, err := os.ReadFile("testdata/tweet_detail.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeline, err := helicon.ParseTimeline(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeline.Instructions) != 3 || timeline.Instructions[1].Type != helicon.InstructionAddEntries {
+		t.Fatalf("unexpected instructions %+v", timeline.Instructions)
+	}
+	if !timeline.Terminated("Top") || timeline.Terminated("Bottom") {
+		t.Error("timeline is terminated at the top only")
+	}
+	entries := timeline.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Content.EntryType != helicon.EntryItem || entries[1].Content.EntryType != helicon.EntryModule || entries[2].Content.EntryType != helicon.EntryCursor {
+		t.Errorf("unexpected entry types %s, %s, %s", entries[0].Content.EntryType, entries[1].Content.EntryType, entries[2].Content.EntryType)
+	}
+	if items := entries[1].Items(); len(items) != 3 || items[0].ItemType != helicon.ItemTweet || items[2].ItemType != helicon.ItemCursor {
+		t.Errorf("unexpected module items %+v", items)
+	}
+	if cursor, _ := timeline.Cursor(helicon.CursorBottom); cursor != "BOTTOM_CURSOR" {
+		t.Errorf("unexpected bottom cursor %q", cursor)
+	}
+	if cursor, _ := timeline.Cursor(helicon.CursorShowMoreThreads); cursor != "SHOWMORE_CURSOR" {
+		t.Errorf("unexpected module cursor %q", cursor)
+	}
+	if _, ok := timeline.Cursor(helicon.CursorTop); ok {
+		t.Error("there is no top cursor")
+	}
+
+	if _, err := helicon.ParseTimeline([]byte(`{"data":{"user":{"result":{}}}}`)); !errors.Is(err, helicon.ErrNoTimeline) {
+		t.Errorf("expected ErrNoTimeline, got %v", err)
+	}
+}
+
+func TestTimeline_Entries(t *testing.T) {
+	t.Parallel()
+	timeline, err := helicon.ParseTimeline([]byte(`{"data":{"user":{"result":{"timeline":{"timeline":{"instructions":[
+		{"type":"TimelineAddEntries","entries":[
+			{"entryId":"stale","sortIndex":"1","content":{"entryType":"TimelineTimelineItem"}}]},
+		{"type":"TimelineClearCache"},
+		{"type":"TimelineAddEntries","entries":[
+			{"entryId":"tweet-9","sortIndex":"9","content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineTweet"}}},
+			{"entryId":"tweet-10","sortIndex":"10","content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineTweet","promotedMetadata":{"advertiser_results":{}}}}},
+			{"entryId":"module","sortIndex":"5","content":{"entryType":"TimelineTimelineModule","items":[{"entryId":"module-a","item":{"itemContent":{"itemType":"TimelineUser"}}}]}},
+			{"entryId":"cursor-bottom","sortIndex":"0","content":{"entryType":"TimelineTimelineCursor","value":"old","cursorType":"Bottom"}}]},
+		{"type":"TimelineAddToModule","moduleEntryId":"module","moduleItems":[{"entryId":"module-b","item":{"itemContent":{"itemType":"TimelineUser"}}}]},
+		{"type":"TimelineReplaceEntry","entry_id_to_replace":"cursor-bottom","entry":{"entryId":"cursor-bottom","sortIndex":"0","content":{"entryType":"TimelineTimelineCursor","value":"new","cursorType":"Bottom"}}},
+		{"type":"TimelinePinEntry","entry":{"entryId":"tweet-1","sortIndex":"1","content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineTweet"}}}}
+	]}}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, entry := range timeline.Entries() {
+		ids = append(ids, entry.EntryId)
+	}
+	// sort indexes are compared as numbers, "10" is above "9".
+	want := []string{"tweet-1", "tweet-10", "tweet-9", "module", "cursor-bottom"}
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, ids)
+		}
+	}
+	entries := timeline.Entries()
+	if !entries[1].Items()[0].Promoted() || entries[2].Items()[0].Promoted() {
+		t.Error("only items with promoted metadata are ads")
+	}
+	if items := entries[3].Items(); len(items) != 2 {
+		t.Errorf("module items must be added to the module, got %d", len(items))
+	}
+	if cursor, _ := timeline.Cursor(helicon.CursorBottom); cursor != "new" {
+		t.Errorf("replaced cursor must be used, got %q", cursor)
+	}
+	if len(timeline.Instructions[2].Entries[2].Content.Items) != 1 {
+		t.Error("applying instructions must not modify them")
+	}
+}
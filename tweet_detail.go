@@ -1,41 +1,47 @@
 package helicon
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
 )
 
-func (h *Helicon) GetTweetDetails(request TweetDetailRequest) (*TweetDetailResponse, error) {
-	uri, err := request.GetURL()
-	if err != nil {
-		return nil, err
-	}
-	body, err := h.hitApi(*uri)
+// GetTweetDetails fetches the conversation of [TweetDetailVariables.FocalTweetId].
+//
+// If X rejects the request for missing features or a rotated query ID, request is fixed and retried once, see [Helicon.OnHeal].
+// Long conversations are cheaper with [Helicon.StreamTweetDetails]. Pass [WithEnvelope] to keep the raw response.
+func (h *Helicon) GetTweetDetails(request TweetDetailRequest, opts ...ExecuteOption) (*TweetDetailResponse, error) {
+	op, err := request.Operation()
 	if err != nil {
 		return nil, err
 	}
 	var response TweetDetailResponse
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response body: %w", err)
+	if err := h.Execute(context.Background(), op, &response.Data, opts...); err != nil {
+		return nil, err
 	}
 	return &response, nil
 }
 
+// QueryId of TweetDetail at the time of writing. X rotates these, current one is discovered from web client bundles
+// through [QueryRegistry], this is only used if discovery fails.
 const QueryId = "1RFzrZSUoVSgHzVK4MHWlg"
 
 type TweetDetailVariables struct {
 	// Unique identifier of the main tweet for which you want to fetch details.
 	// Every tweet on X has a unique ID. This is the primary input for the TweetDetail query.
 	FocalTweetId string `json:"focalTweetId"` // main tweet id
+	// Cursor of the conversation page to fetch, taken from a cursor entry of the previous page. Empty for the first page.
+	//
+	// Default: empty
+	Cursor string `json:"cursor,omitempty"`
 	// Indicates the context or page from which the user navigated to view the tweet details.
 	// For example, `home`: The user clicked on the tweet from their main home timeline.
 	//
 	// X might use this for analytics, for slightly different UI presentations, or to determine what content to load around the tweet.
 	//
-	// Default: "home"
-	Referrer string `json:"referrer,omitempty"`
+	// Default: empty
+	Referrer Referrer `json:"referrer,omitempty"`
+	// Base64 encoded blob web client sends along with [TweetDetailVariables.Referrer] to describe where the tweet was clicked.
+	//
 	// Default: empty
 	ControllerData string `json:"controller_data,omitempty"`
 	// "RUX" often stands for "Rich User Experience." "Injections" is dynamically adding or modifying content or UI elements.
@@ -54,7 +60,7 @@ type TweetDetailVariables struct {
 	// Likes: Most likes to least.
 	//
 	// Default: "Relevance"
-	RankingMode string `json:"rankingMode"` // reply ranking mode, relevance by default, can be empty
+	RankingMode RankingMode `json:"rankingMode"` // reply ranking mode, relevance by default, can be empty
 	// Determines whether promoted tweets (advertisements) should be included in the data returned,
 	// particularly in the context of replies or related tweets shown alongside the focal tweet.
 	//
@@ -83,13 +89,15 @@ type TweetDetailVariables struct {
 	WithVoice bool `json:"withVoice"` // uhhh
 }
 
+// NewTweetDetailVariables returns variables of the first page of tweetId's conversation, see [TweetDetailVariables.WithCursor]
+// for the next ones, and [NewTweetDetailVariablesBuilder] for validated variables.
 func NewTweetDetailVariables(tweetId string) TweetDetailVariables {
 	return TweetDetailVariables{
 		FocalTweetId:                           tweetId,
 		Referrer:                               "",
 		ControllerData:                         "",
 		WithRuxInjections:                      false,
-		RankingMode:                            "Relevance",
+		RankingMode:                            RankingRelevance,
 		IncludePromotedContent:                 true,
 		WithCommunity:                          true,
 		WithQuickPromoteEligibilityTweetFields: true,
@@ -186,398 +194,85 @@ func NewTweetDetailFieldToggles() TweetDetailFieldToggles {
 }
 
 type TweetDetailRequest struct {
-	Variables    TweetDetailVariables
-	Features     TweetDetailFeatures
-	FieldToggles TweetDetailFieldToggles
+	Variables TweetDetailVariables
+	// nil sends the switches discovered from web client as they are. if set, every switch in here overrides the
+	// discovered one, including the ones left false.
+	Features *TweetDetailFeatures
+	// applied last, on top of both discovered switches and Features. use this for switches [TweetDetailFeatures] does not have.
+	FeatureOverrides FeatureSet
+	FieldToggles     TweetDetailFieldToggles
 }
 
 func NewTweetDetailRequest(variables TweetDetailVariables, features TweetDetailFeatures, fieldToggles TweetDetailFieldToggles) *TweetDetailRequest {
-	return &TweetDetailRequest{Variables: variables, Features: features, FieldToggles: fieldToggles}
+	return &TweetDetailRequest{Variables: variables, Features: &features, FieldToggles: fieldToggles}
 }
 
+// GetURL builds the request url with query ID and feature switches from [DefaultQueryRegistry], falling back to [QueryId]
+// if registry does not know TweetDetail. registry is not refreshed here, [Helicon.GetTweetDetails] does that.
 func (r TweetDetailRequest) GetURL() (*string, error) {
-	variablesJSON, err := json.Marshal(r.Variables)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling variables: %w", err)
+	queryID, ok := DefaultQueryRegistry.QueryID("TweetDetail")
+	if !ok {
+		queryID = QueryId
 	}
-	encodedVariables := url.QueryEscape(string(variablesJSON))
+	return r.getURL(queryID, DefaultQueryRegistry.OperationFeatures("TweetDetail"))
+}
 
-	featuresJSON, err := json.Marshal(r.Features)
+// FeatureSet returns features that are sent with this request, discovered features are merged under [TweetDetailRequest.Features]
+// and [TweetDetailRequest.FeatureOverrides] on top.
+func (r TweetDetailRequest) FeatureSet(discovered FeatureSet) (FeatureSet, error) {
+	features, err := r.features()
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling features: %w", err)
+		return nil, err
 	}
-	encodedFeatures := url.QueryEscape(string(featuresJSON))
+	return discovered.Merge(features), nil
+}
+
+// features returns the switches set on the request, [TweetDetailRequest.Features] if it is set and
+// [TweetDetailRequest.FeatureOverrides] on top.
+func (r TweetDetailRequest) features() (FeatureSet, error) {
+	var features FeatureSet
+	if r.Features != nil {
+		var err error
+		if features, err = FeatureSetFrom(*r.Features); err != nil {
+			return nil, err
+		}
+	}
+	return features.Merge(r.FeatureOverrides), nil
+}
 
-	fieldTogglesJSON, err := json.Marshal(r.FieldToggles)
+// Operation returns this request as a generic [Operation], see [Helicon.Execute].
+func (r TweetDetailRequest) Operation() (Operation, error) {
+	features, err := r.features()
 	if err != nil {
-		return nil, fmt.Errorf("error marshalling fieldToggles: %w", err)
+		return Operation{}, err
 	}
-	encodedFieldToggles := url.QueryEscape(string(fieldTogglesJSON))
+	return Operation{
+		Name:         "TweetDetail",
+		QueryID:      QueryId,
+		Type:         OperationQuery,
+		Variables:    r.Variables,
+		Features:     features,
+		FieldToggles: r.FieldToggles,
+	}, nil
+}
 
-	baseURL := fmt.Sprintf("https://x.com/i/api/graphql/%s/TweetDetail", QueryId)
-	fullURL := fmt.Sprintf("%s?variables=%s&features=%s&fieldToggles=%s",
-		baseURL, encodedVariables, encodedFeatures, encodedFieldToggles)
+func (r TweetDetailRequest) getURL(queryID string, discovered FeatureSet) (*string, error) {
+	features, err := r.FeatureSet(discovered)
+	if err != nil {
+		return nil, err
+	}
+	fullURL, err := graphQLURL(fmt.Sprintf("https://x.com/i/api/graphql/%s/TweetDetail", queryID), r.Variables, features, r.FieldToggles)
+	if err != nil {
+		return nil, err
+	}
 	return &fullURL, nil
 }
 
 // TweetDetailResponse example => https://paste.rs/GSkCG.json
+//
+// Conversation is a [Timeline], see [Helicon.GetTweetDetailTimeline] for it parsed with the cursors.
 type TweetDetailResponse struct {
 	Data struct {
-		ThreadedConversationWithInjectionsV2 struct {
-			Instructions []struct {
-				Type    string `json:"type"`
-				Entries []struct {
-					EntryId   string `json:"entryId"`
-					SortIndex string `json:"sortIndex"`
-					Content   struct {
-						EntryType   string `json:"entryType"`
-						Typename    string `json:"__typename"`
-						ItemContent struct {
-							ItemType     string `json:"itemType"`
-							Typename     string `json:"__typename"`
-							TweetResults struct {
-								Result struct {
-									Typename string `json:"__typename"`
-									Tweet    struct {
-										RestId            string `json:"rest_id"`
-										HasBirdwatchNotes bool   `json:"has_birdwatch_notes"`
-										Core              struct {
-											UserResults struct {
-												Result struct {
-													Typename                   string `json:"__typename"`
-													Id                         string `json:"id"`
-													RestId                     string `json:"rest_id"`
-													AffiliatesHighlightedLabel struct {
-													} `json:"affiliates_highlighted_label"`
-													HasGraduatedAccess bool `json:"has_graduated_access"`
-													IsBlueVerified     bool `json:"is_blue_verified"`
-													Legacy             struct {
-														CanDm               bool   `json:"can_dm"`
-														CanMediaTag         bool   `json:"can_media_tag"`
-														CreatedAt           string `json:"created_at"`
-														DefaultProfile      bool   `json:"default_profile"`
-														DefaultProfileImage bool   `json:"default_profile_image"`
-														Description         string `json:"description"`
-														Entities            struct {
-															Description struct {
-																Urls []interface{} `json:"urls"`
-															} `json:"description"`
-															Url struct {
-																Urls []struct {
-																	DisplayUrl  string `json:"display_url"`
-																	ExpandedUrl string `json:"expanded_url"`
-																	Url         string `json:"url"`
-																	Indices     []int  `json:"indices"`
-																} `json:"urls"`
-															} `json:"url"`
-														} `json:"entities"`
-														FastFollowersCount      int           `json:"fast_followers_count"`
-														FavouritesCount         int           `json:"favourites_count"`
-														FollowersCount          int           `json:"followers_count"`
-														Following               bool          `json:"following"`
-														FriendsCount            int           `json:"friends_count"`
-														HasCustomTimelines      bool          `json:"has_custom_timelines"`
-														IsTranslator            bool          `json:"is_translator"`
-														ListedCount             int           `json:"listed_count"`
-														MediaCount              int           `json:"media_count"`
-														Name                    string        `json:"name"`
-														NormalFollowersCount    int           `json:"normal_followers_count"`
-														PinnedTweetIdsStr       []string      `json:"pinned_tweet_ids_str"`
-														PossiblySensitive       bool          `json:"possibly_sensitive"`
-														ProfileBannerUrl        string        `json:"profile_banner_url"`
-														ProfileImageUrlHttps    string        `json:"profile_image_url_https"`
-														ProfileInterstitialType string        `json:"profile_interstitial_type"`
-														ScreenName              string        `json:"screen_name"`
-														StatusesCount           int           `json:"statuses_count"`
-														TranslatorType          string        `json:"translator_type"`
-														Url                     string        `json:"url"`
-														WantRetweets            bool          `json:"want_retweets"`
-														WithheldInCountries     []interface{} `json:"withheld_in_countries"`
-													} `json:"legacy"`
-													Location struct {
-														Location string `json:"location"`
-													} `json:"location"`
-													ParodyCommentaryFanLabel string `json:"parody_commentary_fan_label"`
-													ProfileImageShape        string `json:"profile_image_shape"`
-													Privacy                  struct {
-														Protected bool `json:"protected"`
-													} `json:"privacy"`
-													TipjarSettings struct {
-													} `json:"tipjar_settings"`
-													Verification struct {
-														Verified bool `json:"verified"`
-													} `json:"verification"`
-													VerifiedPhoneStatus bool `json:"verified_phone_status"`
-												} `json:"result"`
-											} `json:"user_results"`
-										} `json:"core"`
-										UnmentionData struct {
-										} `json:"unmention_data"`
-										EditControl struct {
-											EditTweetIds       []string `json:"edit_tweet_ids"`
-											EditableUntilMsecs string   `json:"editable_until_msecs"`
-											IsEditEligible     bool     `json:"is_edit_eligible"`
-											EditsRemaining     string   `json:"edits_remaining"`
-										} `json:"edit_control"`
-										IsTranslatable bool `json:"is_translatable"`
-										Views          struct {
-											Count string `json:"count"`
-											State string `json:"state"`
-										} `json:"views"`
-										Source        string `json:"source"`
-										AwardEligible bool   `json:"award_eligible"`
-										GrantedAwards struct {
-										} `json:"granted_awards"`
-										GrokAnalysisButton bool `json:"grok_analysis_button"`
-										Legacy             struct {
-											BookmarkCount       int    `json:"bookmark_count"`
-											Bookmarked          bool   `json:"bookmarked"`
-											CreatedAt           string `json:"created_at"`
-											ConversationControl struct {
-												Policy                   string `json:"policy"`
-												ConversationOwnerResults struct {
-													Result struct {
-														Typename string `json:"__typename"`
-														Legacy   struct {
-															ScreenName string `json:"screen_name"`
-														} `json:"legacy"`
-													} `json:"result"`
-												} `json:"conversation_owner_results"`
-											} `json:"conversation_control"`
-											ConversationIdStr string `json:"conversation_id_str"`
-											DisplayTextRange  []int  `json:"display_text_range"`
-											Entities          struct {
-												Hashtags     []interface{} `json:"hashtags"`
-												Symbols      []interface{} `json:"symbols"`
-												Timestamps   []interface{} `json:"timestamps"`
-												Urls         []interface{} `json:"urls"`
-												UserMentions []interface{} `json:"user_mentions"`
-											} `json:"entities"`
-											FavoriteCount int    `json:"favorite_count"`
-											Favorited     bool   `json:"favorited"`
-											FullText      string `json:"full_text"`
-											IsQuoteStatus bool   `json:"is_quote_status"`
-											Lang          string `json:"lang"`
-											QuoteCount    int    `json:"quote_count"`
-											ReplyCount    int    `json:"reply_count"`
-											RetweetCount  int    `json:"retweet_count"`
-											Retweeted     bool   `json:"retweeted"`
-											UserIdStr     string `json:"user_id_str"`
-											IdStr         string `json:"id_str"`
-										} `json:"legacy"`
-										QuickPromoteEligibility struct {
-											Eligibility string `json:"eligibility"`
-										} `json:"quick_promote_eligibility"`
-									} `json:"tweet"`
-									LimitedActionResults struct {
-										LimitedActions []struct {
-											Action string `json:"action"`
-											Prompt struct {
-												Typename string `json:"__typename"`
-												CtaType  string `json:"cta_type"`
-												Headline struct {
-													Text     string        `json:"text"`
-													Entities []interface{} `json:"entities"`
-												} `json:"headline"`
-												Subtext struct {
-													Text     string        `json:"text"`
-													Entities []interface{} `json:"entities"`
-												} `json:"subtext"`
-											} `json:"prompt"`
-										} `json:"limited_actions"`
-									} `json:"limitedActionResults"`
-								} `json:"result"`
-							} `json:"tweet_results"`
-							TweetDisplayType    string `json:"tweetDisplayType"`
-							HasModeratedReplies bool   `json:"hasModeratedReplies"`
-						} `json:"itemContent,omitempty"`
-						ClientEventInfo struct {
-							Component string `json:"component"`
-							Element   string `json:"element"`
-							Details   struct {
-								ConversationDetails struct {
-									ConversationSection string `json:"conversationSection"`
-								} `json:"conversationDetails"`
-							} `json:"details,omitempty"`
-						} `json:"clientEventInfo"`
-						Items []struct {
-							EntryId string `json:"entryId"`
-							Item    struct {
-								ItemContent struct {
-									ItemType     string `json:"itemType"`
-									Typename     string `json:"__typename"`
-									TweetResults struct {
-										Result struct {
-											Typename          string `json:"__typename"`
-											RestId            string `json:"rest_id"`
-											HasBirdwatchNotes bool   `json:"has_birdwatch_notes"`
-											Core              struct {
-												UserResults struct {
-													Result struct {
-														Typename                   string `json:"__typename"`
-														Id                         string `json:"id"`
-														RestId                     string `json:"rest_id"`
-														AffiliatesHighlightedLabel struct {
-														} `json:"affiliates_highlighted_label"`
-														HasGraduatedAccess bool `json:"has_graduated_access"`
-														IsBlueVerified     bool `json:"is_blue_verified"`
-														Legacy             struct {
-															CanDm               bool   `json:"can_dm"`
-															CanMediaTag         bool   `json:"can_media_tag"`
-															CreatedAt           string `json:"created_at"`
-															DefaultProfile      bool   `json:"default_profile"`
-															DefaultProfileImage bool   `json:"default_profile_image"`
-															Description         string `json:"description"`
-															Entities            struct {
-																Description struct {
-																	Urls []interface{} `json:"urls"`
-																} `json:"description"`
-																Url struct {
-																	Urls []struct {
-																		DisplayUrl  string `json:"display_url"`
-																		ExpandedUrl string `json:"expanded_url"`
-																		Url         string `json:"url"`
-																		Indices     []int  `json:"indices"`
-																	} `json:"urls"`
-																} `json:"url"`
-															} `json:"entities"`
-															FastFollowersCount      int           `json:"fast_followers_count"`
-															FavouritesCount         int           `json:"favourites_count"`
-															FollowersCount          int           `json:"followers_count"`
-															Following               bool          `json:"following"`
-															FriendsCount            int           `json:"friends_count"`
-															HasCustomTimelines      bool          `json:"has_custom_timelines"`
-															IsTranslator            bool          `json:"is_translator"`
-															ListedCount             int           `json:"listed_count"`
-															MediaCount              int           `json:"media_count"`
-															Name                    string        `json:"name"`
-															NormalFollowersCount    int           `json:"normal_followers_count"`
-															PinnedTweetIdsStr       []interface{} `json:"pinned_tweet_ids_str"`
-															PossiblySensitive       bool          `json:"possibly_sensitive"`
-															ProfileBannerUrl        string        `json:"profile_banner_url"`
-															ProfileImageUrlHttps    string        `json:"profile_image_url_https"`
-															ProfileInterstitialType string        `json:"profile_interstitial_type"`
-															ScreenName              string        `json:"screen_name"`
-															StatusesCount           int           `json:"statuses_count"`
-															TranslatorType          string        `json:"translator_type"`
-															Url                     string        `json:"url"`
-															WantRetweets            bool          `json:"want_retweets"`
-															WithheldInCountries     []interface{} `json:"withheld_in_countries"`
-														} `json:"legacy"`
-														Location struct {
-															Location string `json:"location"`
-														} `json:"location"`
-														ParodyCommentaryFanLabel string `json:"parody_commentary_fan_label"`
-														ProfileImageShape        string `json:"profile_image_shape"`
-														Privacy                  struct {
-															Protected bool `json:"protected"`
-														} `json:"privacy"`
-														TipjarSettings struct {
-															IsEnabled     bool   `json:"is_enabled"`
-															PatreonHandle string `json:"patreon_handle,omitempty"`
-															CashAppHandle string `json:"cash_app_handle,omitempty"`
-															VenmoHandle   string `json:"venmo_handle,omitempty"`
-														} `json:"tipjar_settings"`
-														Verification struct {
-															Verified bool `json:"verified"`
-														} `json:"verification"`
-														VerifiedPhoneStatus bool `json:"verified_phone_status"`
-													} `json:"result"`
-												} `json:"user_results"`
-											} `json:"core"`
-											UnmentionData struct {
-											} `json:"unmention_data"`
-											EditControl struct {
-												EditTweetIds       []string `json:"edit_tweet_ids"`
-												EditableUntilMsecs string   `json:"editable_until_msecs"`
-												IsEditEligible     bool     `json:"is_edit_eligible"`
-												EditsRemaining     string   `json:"edits_remaining"`
-											} `json:"edit_control"`
-											IsTranslatable bool `json:"is_translatable"`
-											Views          struct {
-												Count string `json:"count"`
-												State string `json:"state"`
-											} `json:"views"`
-											Source        string `json:"source"`
-											AwardEligible bool   `json:"award_eligible"`
-											GrantedAwards struct {
-											} `json:"granted_awards"`
-											GrokAnalysisButton bool `json:"grok_analysis_button"`
-											Legacy             struct {
-												BookmarkCount       int    `json:"bookmark_count"`
-												Bookmarked          bool   `json:"bookmarked"`
-												CreatedAt           string `json:"created_at"`
-												ConversationControl struct {
-													Policy                   string `json:"policy"`
-													ConversationOwnerResults struct {
-														Result struct {
-															Typename string `json:"__typename"`
-															Legacy   struct {
-																ScreenName string `json:"screen_name"`
-															} `json:"legacy"`
-														} `json:"result"`
-													} `json:"conversation_owner_results"`
-												} `json:"conversation_control"`
-												ConversationIdStr string `json:"conversation_id_str"`
-												DisplayTextRange  []int  `json:"display_text_range"`
-												Entities          struct {
-													Hashtags     []interface{} `json:"hashtags"`
-													Symbols      []interface{} `json:"symbols"`
-													Timestamps   []interface{} `json:"timestamps"`
-													Urls         []interface{} `json:"urls"`
-													UserMentions []struct {
-														IdStr      string `json:"id_str"`
-														Name       string `json:"name"`
-														ScreenName string `json:"screen_name"`
-														Indices    []int  `json:"indices"`
-													} `json:"user_mentions"`
-												} `json:"entities"`
-												FavoriteCount        int    `json:"favorite_count"`
-												Favorited            bool   `json:"favorited"`
-												FullText             string `json:"full_text"`
-												InReplyToScreenName  string `json:"in_reply_to_screen_name"`
-												InReplyToStatusIdStr string `json:"in_reply_to_status_id_str"`
-												InReplyToUserIdStr   string `json:"in_reply_to_user_id_str"`
-												IsQuoteStatus        bool   `json:"is_quote_status"`
-												Lang                 string `json:"lang"`
-												QuoteCount           int    `json:"quote_count"`
-												ReplyCount           int    `json:"reply_count"`
-												RetweetCount         int    `json:"retweet_count"`
-												Retweeted            bool   `json:"retweeted"`
-												UserIdStr            string `json:"user_id_str"`
-												IdStr                string `json:"id_str"`
-											} `json:"legacy"`
-											QuickPromoteEligibility struct {
-												Eligibility string `json:"eligibility"`
-											} `json:"quick_promote_eligibility"`
-										} `json:"result"`
-									} `json:"tweet_results"`
-									TweetDisplayType string `json:"tweetDisplayType"`
-									SocialContext    struct {
-										Type        string `json:"type"`
-										ContextType string `json:"contextType"`
-										Text        string `json:"text"`
-									} `json:"socialContext"`
-								} `json:"itemContent"`
-								ClientEventInfo struct {
-									Component string `json:"component"`
-									Element   string `json:"element"`
-									Details   struct {
-										ConversationDetails struct {
-											ConversationSection string `json:"conversationSection"`
-										} `json:"conversationDetails"`
-										TimelinesDetails struct {
-											ControllerData string `json:"controllerData"`
-										} `json:"timelinesDetails"`
-									} `json:"details"`
-								} `json:"clientEventInfo"`
-							} `json:"item"`
-						} `json:"items,omitempty"`
-						DisplayType string `json:"displayType,omitempty"`
-					} `json:"content"`
-				} `json:"entries,omitempty"`
-				Direction string `json:"direction,omitempty"`
-			} `json:"instructions"`
-		} `json:"threaded_conversation_with_injections_v2"`
+		ThreadedConversationWithInjectionsV2 Timeline `json:"threaded_conversation_with_injections_v2"`
 	} `json:"data"`
 }
@@ -0,0 +1,182 @@
+package helicon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
+)
+
+// StreamTweetDetails is [Helicon.GetTweetDetails] that yields conversation entries one by one while they are read from
+// the connection. Neither the response nor the whole conversation is ever held in memory, and nothing but entries is
+// decoded, rest of the response is skipped token by token. Worth it for long conversations, see the benchmarks.
+//
+//	for entry, err := range h.StreamTweetDetails(ctx, request) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Breaking out of the loop stops reading and closes the connection. Requests are healed like [Helicon.Execute] does.
+func (h *Helicon) StreamTweetDetails(ctx context.Context, request TweetDetailRequest) iter.Seq2[*TimelineEntry, error] {
+	return func(yield func(*TimelineEntry, error) bool) {
+		op, err := request.Operation()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		body, err := graphQLSend(h, op.Name, op.QueryID, func(queryID string, discovered FeatureSet) (*http.Request, error) {
+			return h.newGraphQLRequest(ctx, op, queryID, discovered)
+		}, h.stream)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer closeBody(body)
+		err = decodeTweetDetailEntries(body, func(entry *TimelineEntry) bool {
+			return yield(entry, nil)
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// errStopStreaming unwinds the decoder when the consumer does not want more entries.
+var errStopStreaming = errors.New("stop streaming")
+
+// decodeTweetDetailEntries calls yield with every `data.threaded_conversation_with_injections_v2.instructions[].entries[]`
+// of a TweetDetail response read from r, until yield returns false. entries are decoded with encoding/json semantics.
+func decodeTweetDetailEntries(r io.Reader, yield func(*TimelineEntry) bool) error {
+	dec := jsontext.NewDecoder(r, jsonv1.DefaultOptionsV1())
+	var graphQLErrors GraphQLErrors
+	hasData := false
+	entries := func() error {
+		entry := new(TimelineEntry)
+		if err := jsonv2.UnmarshalDecode(dec, entry); err != nil {
+			return fmt.Errorf("failed to decode TweetDetail entry: %w", err)
+		}
+		if !yield(entry) {
+			return errStopStreaming
+		}
+		return nil
+	}
+	err := streamObject(dec, func(key string) error {
+		switch key {
+		case "data":
+			hasData = dec.PeekKind() == '{'
+			return streamPath(dec, []string{"threaded_conversation_with_injections_v2", "instructions", "[]", "entries", "[]"}, entries)
+		case "errors":
+			return jsonv2.UnmarshalDecode(dec, &graphQLErrors)
+		}
+		return dec.SkipValue()
+	})
+	if errors.Is(err, errStopStreaming) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode TweetDetail response: %w", err)
+	}
+	if !hasData {
+		if len(graphQLErrors) > 0 {
+			return graphQLErrors
+		}
+		return fmt.Errorf("TweetDetail response has neither data nor errors")
+	}
+	if len(graphQLErrors) > 0 {
+		slog.Warn("GraphQL response has errors along with data", "operation", "TweetDetail", "errors", graphQLErrors.Error())
+	}
+	return nil
+}
+
+// streamPath descends into the next value along path and calls fn at the end of it. object keys not on path are skipped,
+// "[]" goes through every element of an array. null anywhere along the path is an empty value.
+func streamPath(dec *jsontext.Decoder, path []string, fn func() error) error {
+	if len(path) == 0 {
+		return fn()
+	}
+	if path[0] == "[]" {
+		return streamArray(dec, func() error {
+			return streamPath(dec, path[1:], fn)
+		})
+	}
+	return streamObject(dec, func(key string) error {
+		if key != path[0] {
+			return dec.SkipValue()
+		}
+		return streamPath(dec, path[1:], fn)
+	})
+}
+
+// streamFind goes through the next value and calls fn with every object key match is true for, wherever it is. fn must
+// read or skip the value, rest of the value is searched further.
+func streamFind(dec *jsontext.Decoder, match func(key string) bool, fn func(key string) error) error {
+	switch dec.PeekKind() {
+	case '{':
+		return streamObject(dec, func(key string) error {
+			if match(key) {
+				return fn(key)
+			}
+			return streamFind(dec, match, fn)
+		})
+	case '[':
+		return streamArray(dec, func() error {
+			return streamFind(dec, match, fn)
+		})
+	}
+	return dec.SkipValue()
+}
+
+// streamObject reads an object and calls fn with every key, fn must read or skip the value.
+func streamObject(dec *jsontext.Decoder, fn func(key string) error) error {
+	if open, err := readDelim(dec, '{'); !open {
+		return err
+	}
+	for dec.PeekKind() != '}' {
+		key, err := dec.ReadToken()
+		if err != nil {
+			return err
+		}
+		if err := fn(key.String()); err != nil {
+			return err
+		}
+	}
+	_, err := dec.ReadToken()
+	return err
+}
+
+// streamArray reads an array and calls fn for every element, fn must read or skip the element.
+func streamArray(dec *jsontext.Decoder, fn func() error) error {
+	if open, err := readDelim(dec, '['); !open {
+		return err
+	}
+	for dec.PeekKind() != ']' {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	_, err := dec.ReadToken()
+	return err
+}
+
+// readDelim reads the opening delim of an object or array, false if it is null, or not there.
+func readDelim(dec *jsontext.Decoder, delim jsontext.Kind) (bool, error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return false, err
+	}
+	switch tok.Kind() {
+	case delim:
+		return true, nil
+	case 'n':
+		return false, nil
+	}
+	return false, fmt.Errorf("expected %v at %s, got %v", delim, dec.StackPointer(), tok.Kind())
+}
@@ -0,0 +1,112 @@
+package helicon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tweetDetailBody builds a TweetDetail response with n entries, plus the kind of subtrees stream decoding skips.
+func tweetDetailBody(tb testing.TB, n int) []byte {
+	tb.Helper()
+	var entries []string
+	for i := range n {
+		var entry TimelineEntry
+		entry.EntryId = fmt.Sprintf("tweet-%d", i)
+		entry.SortIndex = fmt.Sprint(n - i)
+		tweet := &RawTweet{RestId: fmt.Sprint(i)}
+		tweet.Legacy.FullText = strings.Repeat("lorem ipsum ", 20)
+		entry.Content.ItemContent = &TimelineItemContent{
+			ItemType:     ItemTweet,
+			TweetResults: &TweetResults{Result: &TweetResult{Typename: TweetResultTweet, Tweet: tweet}},
+		}
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		entries = append(entries, string(raw))
+	}
+	return fmt.Appendf(nil, `{"data":{"threaded_conversation_with_injections_v2":{"instructions":[`+
+		`{"type":"TimelineClearCache"},{"type":"TimelineAddEntries","entries":[%s]},{"type":"TimelineTerminateTimeline","direction":"Top"}],`+
+		`"metadata":{"reader_mode_config":{"is_reader_mode_available":false}}}},"extensions":{"trace":[1,2,{"a":null}]}}`,
+		strings.Join(entries, ","))
+}
+
+func TestDecodeTweetDetailEntries(t *testing.T) {
+	t.Parallel()
+	body := tweetDetailBody(t, 5)
+	var response TweetDetailResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	var streamed []TimelineEntry
+	err := decodeTweetDetailEntries(bytes.NewReader(body), func(entry *TimelineEntry) bool {
+		streamed = append(streamed, *entry)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := response.Data.ThreadedConversationWithInjectionsV2.Instructions[1].Entries; !reflect.DeepEqual(streamed, want) {
+		t.Fatalf("streamed %d entries that differ from decoded %d", len(streamed), len(want))
+	}
+
+	count := 0
+	err = decodeTweetDetailEntries(bytes.NewReader(body), func(*TimelineEntry) bool {
+		count++
+		return count < 2
+	})
+	if err != nil || count != 2 {
+		t.Fatalf("stopping must not be an error, got %v after %d entries", err, count)
+	}
+
+	err = decodeTweetDetailEntries(strings.NewReader(`{"errors":[{"message":"Rate limit exceeded","code":88}]}`), nil)
+	var graphQLErrors GraphQLErrors
+	if !errors.As(err, &graphQLErrors) || graphQLErrors[0].Code != 88 {
+		t.Fatalf("expected GraphQL errors, got %v", err)
+	}
+	if err := decodeTweetDetailEntries(bytes.NewReader(body[:len(body)/2]), func(*TimelineEntry) bool { return true }); err == nil {
+		t.Fatal("truncated response must fail")
+	}
+}
+
+// BenchmarkTweetDetailDecode compares reading the whole body and decoding it twice, which is what
+// [Helicon.GetTweetDetails] does, against [Helicon.StreamTweetDetails].
+func BenchmarkTweetDetailDecode(b *testing.B) {
+	for _, n := range []int{20, 500} {
+		body := tweetDetailBody(b, n)
+		b.Run(fmt.Sprintf("full/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for b.Loop() {
+				raw, err := io.ReadAll(bytes.NewReader(body))
+				if err != nil {
+					b.Fatal(err)
+				}
+				var envelope graphQLResponse
+				if err := json.Unmarshal(raw, &envelope); err != nil {
+					b.Fatal(err)
+				}
+				var response TweetDetailResponse
+				if err := json.Unmarshal(envelope.Data, &response.Data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("stream/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for b.Loop() {
+				err := decodeTweetDetailEntries(bytes.NewReader(body), func(*TimelineEntry) bool { return true })
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package helicon
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RankingMode is how replies of a conversation are sorted, see [TweetDetailVariables.RankingMode].
+type RankingMode string
+
+const (
+	// RankingRelevance sorts by engagement, recency and connections, what web client does by default.
+	RankingRelevance RankingMode = "Relevance"
+	// RankingRecency sorts from new to old.
+	RankingRecency RankingMode = "Recency"
+	// RankingLikes sorts from most liked to least.
+	RankingLikes RankingMode = "Likes"
+)
+
+// RankingModes are every [RankingMode] X accepts.
+var RankingModes = []RankingMode{RankingRelevance, RankingRecency, RankingLikes}
+
+// Referrer is the page a tweet is opened from, see [TweetDetailVariables.Referrer]. Values are the ones web client sends.
+type Referrer string
+
+const (
+	ReferrerNone          Referrer = ""
+	ReferrerHome          Referrer = "home"
+	ReferrerTweet         Referrer = "tweet"
+	ReferrerProfile       Referrer = "profile"
+	ReferrerSearch        Referrer = "search"
+	ReferrerNotifications Referrer = "notifications"
+	ReferrerBookmarks     Referrer = "bookmarks"
+	ReferrerList          Referrer = "list"
+	ReferrerCommunities   Referrer = "communities"
+	ReferrerMessages      Referrer = "messages"
+)
+
+// Referrers are every [Referrer] web client sends.
+var Referrers = []Referrer{
+	ReferrerNone, ReferrerHome, ReferrerTweet, ReferrerProfile, ReferrerSearch, ReferrerNotifications,
+	ReferrerBookmarks, ReferrerList, ReferrerCommunities, ReferrerMessages,
+}
+
+// ErrInvalidTweetDetailVariables is wrapped by every error of [TweetDetailVariables.Validate].
+var ErrInvalidTweetDetailVariables = errors.New("invalid TweetDetail variables")
+
+// WithCursor returns a copy of v that requests the conversation page at cursor, empty cursor is the first page.
+func (v TweetDetailVariables) WithCursor(cursor string) TweetDetailVariables {
+	v.Cursor = cursor
+	return v
+}
+
+// Validate returns every problem of v joined, each wrapping [ErrInvalidTweetDetailVariables]. X either rejects these,
+// or silently answers with something other than what was asked for.
+func (v TweetDetailVariables) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidTweetDetailVariables, fmt.Sprintf(format, args...)))
+	}
+	if v.FocalTweetId == "" {
+		invalid("focal tweet ID is required")
+	} else if strings.Trim(v.FocalTweetId, "0123456789") != "" {
+		invalid("focal tweet ID %q is not numeric", v.FocalTweetId)
+	}
+	// empty ranking mode is relevance for X.
+	if v.RankingMode != "" && !slices.Contains(RankingModes, v.RankingMode) {
+		invalid("unknown ranking mode %q", v.RankingMode)
+	}
+	if !slices.Contains(Referrers, v.Referrer) {
+		invalid("unknown referrer %q", v.Referrer)
+	}
+	if v.ControllerData != "" {
+		if v.Referrer == ReferrerNone {
+			invalid("controller data describes where the tweet was opened from, it needs a referrer")
+		}
+		if _, err := base64.StdEncoding.DecodeString(v.ControllerData); err != nil {
+			invalid("controller data is not base64: %s", err)
+		}
+	}
+	if v.Cursor != "" && strings.TrimSpace(v.Cursor) != v.Cursor {
+		invalid("cursor %q has surrounding whitespace", v.Cursor)
+	}
+	return errors.Join(errs...)
+}
+
+// TweetDetailVariablesBuilder builds [TweetDetailVariables] starting from [NewTweetDetailVariables], and validates them
+// on [TweetDetailVariablesBuilder.Build].
+//
+//	variables, err := helicon.NewTweetDetailVariablesBuilder(tweetId).
+//		RankingMode(helicon.RankingRecency).
+//		Cursor(cursor).
+//		Build()
+type TweetDetailVariablesBuilder struct {
+	variables TweetDetailVariables
+}
+
+func NewTweetDetailVariablesBuilder(tweetId string) *TweetDetailVariablesBuilder {
+	return &TweetDetailVariablesBuilder{variables: NewTweetDetailVariables(tweetId)}
+}
+
+func (b *TweetDetailVariablesBuilder) RankingMode(mode RankingMode) *TweetDetailVariablesBuilder {
+	b.variables.RankingMode = mode
+	return b
+}
+
+// Referrer sets where the tweet was opened from, controllerData is optional.
+func (b *TweetDetailVariablesBuilder) Referrer(referrer Referrer, controllerData string) *TweetDetailVariablesBuilder {
+	b.variables.Referrer, b.variables.ControllerData = referrer, controllerData
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) Cursor(cursor string) *TweetDetailVariablesBuilder {
+	b.variables.Cursor = cursor
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) WithRuxInjections(enabled bool) *TweetDetailVariablesBuilder {
+	b.variables.WithRuxInjections = enabled
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) IncludePromotedContent(enabled bool) *TweetDetailVariablesBuilder {
+	b.variables.IncludePromotedContent = enabled
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) WithCommunity(enabled bool) *TweetDetailVariablesBuilder {
+	b.variables.WithCommunity = enabled
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) WithQuickPromoteEligibilityTweetFields(enabled bool) *TweetDetailVariablesBuilder {
+	b.variables.WithQuickPromoteEligibilityTweetFields = enabled
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) WithBirdwatchNotes(enabled bool) *TweetDetailVariablesBuilder {
+	b.variables.WithBirdwatchNotes = enabled
+	return b
+}
+
+func (b *TweetDetailVariablesBuilder) WithVoice(enabled bool) *TweetDetailVariablesBuilder {
+	b.variables.WithVoice = enabled
+	return b
+}
+
+// Build returns the variables, or every validation error of them, see [TweetDetailVariables.Validate].
+func (b *TweetDetailVariablesBuilder) Build() (TweetDetailVariables, error) {
+	if err := b.variables.Validate(); err != nil {
+		return TweetDetailVariables{}, err
+	}
+	return b.variables, nil
+}
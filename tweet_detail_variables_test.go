@@ -0,0 +1,78 @@
+package helicon_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/caner-cetin/helicon"
+	"strings"
+	"testing"
+)
+
+func TestTweetDetailVariablesBuilder(t *testing.T) {
+	t.Parallel()
+	variables, err := helicon.NewTweetDetailVariablesBuilder("1790000000000000000").
+		RankingMode(helicon.RankingRecency).
+		Referrer(helicon.ReferrerHome, "DAACDAABDAABCgABAAAAAAAAAAAKAAkAAAAAAAAAAAAA").
+		Cursor("DwAAAPAAAA").
+		WithVoice(false).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"cursor":"DwAAAPAAAA"`, `"rankingMode":"Recency"`, `"referrer":"home"`, `"withVoice":false`, `"withCommunity":true`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("%s does not have %s", raw, want)
+		}
+	}
+	if first := variables.WithCursor(""); first.Cursor != "" || variables.Cursor == "" {
+		t.Error("WithCursor must return a copy")
+	}
+
+	_, err = helicon.NewTweetDetailVariablesBuilder("@jack").
+		RankingMode("Oldest").
+		Referrer(helicon.ReferrerNone, "not base64!").
+		Build()
+	if !errors.Is(err, helicon.ErrInvalidTweetDetailVariables) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	for _, want := range []string{"not numeric", `unknown ranking mode "Oldest"`, "needs a referrer", "not base64"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if err := helicon.NewTweetDetailVariables("1").Validate(); err != nil {
+		t.Fatalf("defaults must be valid, got %v", err)
+	}
+}
+
+func TestTweetDetailRequest_FeatureSet(t *testing.T) {
+	t.Parallel()
+	discovered := helicon.FeatureSet{"view_counts_everywhere_api_enabled": true, "payments_enabled": true}
+	var request helicon.TweetDetailRequest
+	features, err := request.FeatureSet(discovered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 2 || !features["view_counts_everywhere_api_enabled"] || !features["payments_enabled"] {
+		t.Fatalf("zero request must send discovered switches as they are, got %v", features)
+	}
+	request.Features = &helicon.TweetDetailFeatures{}
+	request.FeatureOverrides = helicon.FeatureSet{"payments_enabled": false}
+	if features, err = request.FeatureSet(discovered); err != nil {
+		t.Fatal(err)
+	}
+	if features["view_counts_everywhere_api_enabled"] || features["payments_enabled"] {
+		t.Fatalf("set features and overrides must override discovered switches, got %v", features)
+	}
+	op, err := helicon.TweetDetailRequest{FeatureOverrides: helicon.FeatureSet{"payments_enabled": false}}.Operation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(op.Features) != 1 {
+		t.Fatalf("operation must carry only switches set on the request, got %v", op.Features)
+	}
+}
@@ -0,0 +1,415 @@
+package helicon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// TweetResultType is `__typename` of a tweet result.
+type TweetResultType string
+
+const (
+	TweetResultTweet TweetResultType = "Tweet"
+	// TweetResultWithVisibility is a tweet with limited actions, replies turned off, or a warning shown before it.
+	TweetResultWithVisibility TweetResultType = "TweetWithVisibilityResults"
+	// TweetResultTombstone is a tweet that is not shown, with the text shown in place of it.
+	TweetResultTombstone TweetResultType = "TweetTombstone"
+	// TweetResultUnavailable is a tweet that is not shown, with a reason.
+	TweetResultUnavailable TweetResultType = "TweetUnavailable"
+)
+
+// TweetResults is `tweet_results` object, wherever a tweet is in a response.
+type TweetResults struct {
+	Result *TweetResult `json:"result,omitempty"`
+}
+
+// TweetResult is `result` of `tweet_results`, one of the [TweetResultType] shapes. Which one it is, is
+// [TweetResult.Typename], and fields of the others are nil:
+//
+//   - Tweet, and LimitedActions and Interstitial for TweetWithVisibilityResults
+//   - Tombstone for TweetTombstone
+//   - Unavailable for TweetUnavailable
+//
+// Tweet is the same for Tweet and TweetWithVisibilityResults, no need to care which one it was. [TweetResult.Err] tells
+// why there is no tweet.
+type TweetResult struct {
+	Typename TweetResultType
+	Tweet    *RawTweet
+	// what you can't do with the tweet, replying to it for example.
+	LimitedActions []LimitedAction
+	// warning shown over the tweet, sensitive content for example.
+	Interstitial *TweetInterstitial
+	Tombstone    *Tombstone
+	Unavailable  *TweetUnavailable
+}
+
+// ErrTweetUnavailable is wrapped by [TweetResult.Err] when there is no tweet in the result.
+var ErrTweetUnavailable = errors.New("tweet is unavailable")
+
+// LimitedAction is an action that is limited on a tweet, with the prompt client shows when it is attempted.
+type LimitedAction struct {
+	Action string `json:"action"`
+	Prompt struct {
+		Typename string   `json:"__typename"`
+		CtaType  string   `json:"cta_type"`
+		Headline RichText `json:"headline"`
+		Subtext  RichText `json:"subtext"`
+	} `json:"prompt"`
+}
+
+// TweetInterstitial is the warning shown over a tweet, until it is clicked.
+type TweetInterstitial struct {
+	Typename    string   `json:"__typename"`
+	DisplayType string   `json:"displayType"`
+	Text        RichText `json:"text"`
+	RevealText  RichText `json:"revealText"`
+}
+
+// Tombstone is shown in place of a tweet, "This Post was deleted by the Post author. Learn more".
+type Tombstone struct {
+	Typename string   `json:"__typename"`
+	Text     RichText `json:"text"`
+}
+
+// UnavailableReason is why a tweet is [TweetResultUnavailable].
+type UnavailableReason string
+
+const (
+	UnavailableSuspended UnavailableReason = "Suspended"
+	UnavailableProtected UnavailableReason = "Protected"
+	// UnavailableNsfwLoggedOut is sensitive content, only shown to logged-in accounts.
+	UnavailableNsfwLoggedOut UnavailableReason = "NsfwLoggedOut"
+)
+
+type TweetUnavailable struct {
+	Reason UnavailableReason `json:"reason"`
+}
+
+// RichText is text X shows in its own UI, prompts, warnings, tombstones. entities are links in it, indices are of
+// UTF-16 code units.
+type RichText struct {
+	Rtl      bool   `json:"rtl,omitempty"`
+	Text     string `json:"text"`
+	Entities []struct {
+		FromIndex int `json:"fromIndex"`
+		ToIndex   int `json:"toIndex"`
+		Ref       struct {
+			Type    string `json:"type"`
+			Url     string `json:"url"`
+			UrlType string `json:"urlType"`
+		} `json:"ref"`
+	} `json:"entities,omitempty"`
+}
+
+// tweetResultJSON has every field of every shape, Tweet ones are embedded as `result` itself is the tweet.
+type tweetResultJSON struct {
+	Typename TweetResultType `json:"__typename,omitempty"`
+	*RawTweet
+	// TweetWithVisibilityResults.
+	Tweet                *RawTweet `json:"tweet,omitempty"`
+	LimitedActionResults *struct {
+		LimitedActions []LimitedAction `json:"limited_actions"`
+	} `json:"limitedActionResults,omitempty"`
+	TweetInterstitial *TweetInterstitial `json:"tweetInterstitial,omitempty"`
+	// TweetTombstone.
+	Tombstone *Tombstone `json:"tombstone,omitempty"`
+	// TweetUnavailable.
+	Reason UnavailableReason `json:"reason,omitempty"`
+}
+
+// driftType makes [DetectDrift] look into results through tweetResultJSON.
+func (r *TweetResult) driftType() reflect.Type {
+	return reflect.TypeFor[tweetResultJSON]()
+}
+
+func (r *TweetResult) UnmarshalJSON(data []byte) error {
+	var raw tweetResultJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = TweetResult{Typename: raw.Typename}
+	switch raw.Typename {
+	case TweetResultWithVisibility:
+		r.Tweet, r.Interstitial = raw.Tweet, raw.TweetInterstitial
+		if raw.LimitedActionResults != nil {
+			r.LimitedActions = raw.LimitedActionResults.LimitedActions
+		}
+	case TweetResultTombstone:
+		r.Tombstone = raw.Tombstone
+	case TweetResultUnavailable:
+		r.Unavailable = &TweetUnavailable{Reason: raw.Reason}
+	default:
+		// Tweet, or a result without __typename, older responses and some nested results don't have it.
+		r.Tweet = raw.RawTweet
+	}
+	return nil
+}
+
+func (r TweetResult) MarshalJSON() ([]byte, error) {
+	raw := tweetResultJSON{Typename: r.Typename}
+	switch r.Typename {
+	case TweetResultWithVisibility:
+		raw.Tweet, raw.TweetInterstitial = r.Tweet, r.Interstitial
+		if r.LimitedActions != nil {
+			raw.LimitedActionResults = &struct {
+				LimitedActions []LimitedAction `json:"limited_actions"`
+			}{r.LimitedActions}
+		}
+	case TweetResultTombstone:
+		raw.Tombstone = r.Tombstone
+	case TweetResultUnavailable:
+		if r.Unavailable != nil {
+			raw.Reason = r.Unavailable.Reason
+		}
+	default:
+		raw.RawTweet = r.Tweet
+	}
+	return json.Marshal(raw)
+}
+
+// Err is nil if there is a tweet in the result, or [ErrTweetUnavailable] with tombstone text or unavailability reason.
+func (r *TweetResult) Err() error {
+	switch {
+	case r == nil:
+		return fmt.Errorf("%w: no result", ErrTweetUnavailable)
+	case r.Tweet != nil:
+		return nil
+	case r.Tombstone != nil:
+		return fmt.Errorf("%w: %s", ErrTweetUnavailable, r.Tombstone.Text.Text)
+	case r.Unavailable != nil:
+		return fmt.Errorf("%w: %s", ErrTweetUnavailable, r.Unavailable.Reason)
+	}
+	return fmt.Errorf("%w: %s result has no tweet", ErrTweetUnavailable, r.Typename)
+}
+
+// UserResults is `user_results` object, wherever a user is in a response.
+type UserResults struct {
+	Result *RawUser `json:"result,omitempty"`
+}
+
+// RawTweet is a tweet as it is in GraphQL responses, see [Tweet] for the flat version of it.
+type RawTweet struct {
+	RestId            string `json:"rest_id"`
+	HasBirdwatchNotes bool   `json:"has_birdwatch_notes,omitempty"`
+	Core              struct {
+		UserResults UserResults `json:"user_results"`
+	} `json:"core"`
+	EditControl struct {
+		EditTweetIds       []string      `json:"edit_tweet_ids,omitempty"`
+		EditableUntilMsecs UnixMilliTime `json:"editable_until_msecs,omitzero"`
+		IsEditEligible     bool          `json:"is_edit_eligible,omitempty"`
+		EditsRemaining     Int64String   `json:"edits_remaining,omitempty"`
+	} `json:"edit_control"`
+	IsTranslatable bool `json:"is_translatable,omitempty"`
+	Views          struct {
+		Count Int64String `json:"count,omitempty"`
+		State string      `json:"state,omitempty"`
+	} `json:"views"`
+	Source             string         `json:"source,omitempty"`
+	GrokAnalysisButton bool           `json:"grok_analysis_button,omitempty"`
+	Legacy             RawTweetLegacy `json:"legacy"`
+	// long tweets, Legacy has only the first 280 characters of them.
+	NoteTweet *struct {
+		IsExpandable     bool `json:"is_expandable"`
+		NoteTweetResults struct {
+			Result *RawNoteTweet `json:"result,omitempty"`
+		} `json:"note_tweet_results"`
+	} `json:"note_tweet,omitempty"`
+	// Community Note shown under the tweet, see [Helicon.GetCommunityNotes] for the rest of them.
+	BirdwatchPivot *BirdwatchPivot `json:"birdwatch_pivot,omitempty"`
+	// link previews and polls.
+	Card *RawCard `json:"card,omitempty"`
+	// quote tweets only.
+	QuotedStatusResult *TweetResults `json:"quoted_status_result,omitempty"`
+	// X Articles, Legacy only has the link to it.
+	Article *struct {
+		ArticleResults struct {
+			Result *RawArticle `json:"result,omitempty"`
+		} `json:"article_results"`
+	} `json:"article,omitempty"`
+	// only there with withQuickPromoteEligibilityTweetFields.
+	QuickPromoteEligibility struct {
+		Eligibility string `json:"eligibility,omitempty"`
+	} `json:"quick_promote_eligibility,omitzero"`
+}
+
+type RawTweetLegacy struct {
+	IdStr               string      `json:"id_str"`
+	UserIdStr           string      `json:"user_id_str"`
+	ConversationIdStr   string      `json:"conversation_id_str"`
+	CreatedAt           TwitterTime `json:"created_at"`
+	FullText            string      `json:"full_text"`
+	DisplayTextRange    []int       `json:"display_text_range,omitempty"`
+	Lang                string      `json:"lang,omitempty"`
+	ConversationControl *struct {
+		Policy                   string `json:"policy"`
+		ConversationOwnerResults struct {
+			Result struct {
+				Typename string `json:"__typename"`
+				Legacy   struct {
+					ScreenName string `json:"screen_name"`
+				} `json:"legacy"`
+			} `json:"result"`
+		} `json:"conversation_owner_results"`
+	} `json:"conversation_control,omitempty"`
+	InReplyToScreenName  string `json:"in_reply_to_screen_name,omitempty"`
+	InReplyToStatusIdStr string `json:"in_reply_to_status_id_str,omitempty"`
+	InReplyToUserIdStr   string `json:"in_reply_to_user_id_str,omitempty"`
+	IsQuoteStatus        bool   `json:"is_quote_status,omitempty"`
+	// quoted tweet, even if it is not available anymore, see [RawTweet.QuotedStatusResult].
+	QuotedStatusIdStr     string `json:"quoted_status_id_str,omitempty"`
+	QuotedStatusPermalink *struct {
+		Url      string `json:"url"`
+		Expanded string `json:"expanded"`
+		Display  string `json:"display"`
+	} `json:"quoted_status_permalink,omitempty"`
+	// retweets only, the tweet that is retweeted. full_text of the retweet is "RT @user: " and truncated text of it.
+	RetweetedStatusResult *TweetResults `json:"retweeted_status_result,omitempty"`
+	BookmarkCount         int           `json:"bookmark_count"`
+	FavoriteCount         int           `json:"favorite_count"`
+	QuoteCount            int           `json:"quote_count"`
+	ReplyCount            int           `json:"reply_count"`
+	RetweetCount          int           `json:"retweet_count"`
+	Bookmarked            bool          `json:"bookmarked,omitempty"`
+	Favorited             bool          `json:"favorited,omitempty"`
+	Retweeted             bool          `json:"retweeted,omitempty"`
+	PossiblySensitive     bool          `json:"possibly_sensitive,omitempty"`
+	Entities              Entities      `json:"entities"`
+	// entities.media only has the first one.
+	ExtendedEntities *struct {
+		Media []RawMedia `json:"media"`
+	} `json:"extended_entities,omitempty"`
+	Place *RawPlace `json:"place,omitempty"`
+}
+
+type RawMedia struct {
+	IdStr         string `json:"id_str"`
+	MediaKey      string `json:"media_key"`
+	Type          string `json:"type"`
+	MediaUrlHttps string `json:"media_url_https"`
+	Url           string `json:"url"`
+	DisplayUrl    string `json:"display_url"`
+	ExpandedUrl   string `json:"expanded_url"`
+	Indices       []int  `json:"indices"`
+	ExtAltText    string `json:"ext_alt_text,omitempty"`
+	OriginalInfo  struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"original_info"`
+	// "thumb", "small", "medium", "large".
+	Sizes map[string]struct {
+		W      int    `json:"w"`
+		H      int    `json:"h"`
+		Resize string `json:"resize"`
+	} `json:"sizes,omitempty"`
+	// videos and GIFs only.
+	VideoInfo *struct {
+		AspectRatio    []int `json:"aspect_ratio"`
+		DurationMillis int   `json:"duration_millis,omitempty"`
+		Variants       []struct {
+			Bitrate     int    `json:"bitrate,omitempty"`
+			ContentType string `json:"content_type"`
+			Url         string `json:"url"`
+		} `json:"variants"`
+	} `json:"video_info,omitempty"`
+	ExtMediaAvailability *struct {
+		Status string `json:"status"`
+		Reason string `json:"reason,omitempty"`
+	} `json:"ext_media_availability,omitempty"`
+}
+
+type RawPlace struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	PlaceType   string `json:"place_type"`
+	Country     string `json:"country"`
+	CountryCode string `json:"country_code"`
+	Url         string `json:"url,omitempty"`
+	BoundingBox *struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	} `json:"bounding_box,omitempty"`
+}
+
+// RawUser is a user as it is in GraphQL responses, see [User] for the flat version of it.
+type RawUser struct {
+	Typename                   string `json:"__typename"`
+	Id                         string `json:"id,omitempty"`
+	RestId                     string `json:"rest_id"`
+	AffiliatesHighlightedLabel struct {
+	} `json:"affiliates_highlighted_label"`
+	HasGraduatedAccess bool `json:"has_graduated_access,omitempty"`
+	IsBlueVerified     bool `json:"is_blue_verified"`
+	// newer responses moved these out of legacy, older ones have them in legacy only.
+	Core struct {
+		Name       string      `json:"name"`
+		ScreenName string      `json:"screen_name"`
+		CreatedAt  TwitterTime `json:"created_at"`
+	} `json:"core,omitzero"`
+	Avatar struct {
+		ImageUrl string `json:"image_url"`
+	} `json:"avatar,omitzero"`
+	Legacy struct {
+		CanDm               bool        `json:"can_dm,omitempty"`
+		CanMediaTag         bool        `json:"can_media_tag,omitempty"`
+		CreatedAt           TwitterTime `json:"created_at,omitzero"`
+		DefaultProfile      bool        `json:"default_profile,omitempty"`
+		DefaultProfileImage bool        `json:"default_profile_image,omitempty"`
+		Description         string      `json:"description,omitempty"`
+		Entities            struct {
+			Description struct {
+				Urls []URLEntity `json:"urls"`
+			} `json:"description"`
+			// only there if the user has a website.
+			Url struct {
+				Urls []URLEntity `json:"urls"`
+			} `json:"url,omitzero"`
+		} `json:"entities"`
+		FastFollowersCount      int           `json:"fast_followers_count"`
+		FavouritesCount         int           `json:"favourites_count"`
+		FollowersCount          int           `json:"followers_count"`
+		Following               bool          `json:"following,omitempty"`
+		FriendsCount            int           `json:"friends_count"`
+		HasCustomTimelines      bool          `json:"has_custom_timelines,omitempty"`
+		IsTranslator            bool          `json:"is_translator,omitempty"`
+		ListedCount             int           `json:"listed_count"`
+		Location                string        `json:"location,omitempty"`
+		MediaCount              int           `json:"media_count"`
+		Name                    string        `json:"name,omitempty"`
+		NormalFollowersCount    int           `json:"normal_followers_count"`
+		PinnedTweetIdsStr       []string      `json:"pinned_tweet_ids_str,omitempty"`
+		PossiblySensitive       bool          `json:"possibly_sensitive,omitempty"`
+		ProfileBannerUrl        string        `json:"profile_banner_url,omitempty"`
+		ProfileImageUrlHttps    string        `json:"profile_image_url_https,omitempty"`
+		ProfileInterstitialType string        `json:"profile_interstitial_type,omitempty"`
+		Protected               bool          `json:"protected,omitempty"`
+		ScreenName              string        `json:"screen_name,omitempty"`
+		StatusesCount           int           `json:"statuses_count"`
+		TranslatorType          string        `json:"translator_type,omitempty"`
+		Url                     string        `json:"url,omitempty"`
+		Verified                bool          `json:"verified,omitempty"`
+		WantRetweets            bool          `json:"want_retweets,omitempty"`
+		WithheldInCountries     []interface{} `json:"withheld_in_countries,omitempty"`
+	} `json:"legacy"`
+	Location struct {
+		Location string `json:"location"`
+	} `json:"location"`
+	ParodyCommentaryFanLabel string `json:"parody_commentary_fan_label,omitempty"`
+	ProfileImageShape        string `json:"profile_image_shape,omitempty"`
+	Privacy                  struct {
+		Protected bool `json:"protected"`
+	} `json:"privacy"`
+	TipjarSettings struct {
+		IsEnabled     bool   `json:"is_enabled,omitempty"`
+		PatreonHandle string `json:"patreon_handle,omitempty"`
+		CashAppHandle string `json:"cash_app_handle,omitempty"`
+		VenmoHandle   string `json:"venmo_handle,omitempty"`
+	} `json:"tipjar_settings"`
+	Verification struct {
+		Verified bool `json:"verified"`
+	} `json:"verification"`
+	VerifiedPhoneStatus bool `json:"verified_phone_status,omitempty"`
+}
@@ -0,0 +1,117 @@
+package helicon_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/caner-cetin/helicon"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestTweetResult(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		json     string
+		typename helicon.TweetResultType
+		err      string
+	}{
+		{"tweet", `{"__typename":"Tweet","rest_id":"1","legacy":{"full_text":"hi"}}`, helicon.TweetResultTweet, ""},
+		{"no typename", `{"rest_id":"1","legacy":{"full_text":"hi"}}`, "", ""},
+		{"visibility", `{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"1","legacy":{"full_text":"hi"}},` +
+			`"limitedActionResults":{"limited_actions":[{"action":"Reply"}]}}`, helicon.TweetResultWithVisibility, ""},
+		{"tombstone", `{"__typename":"TweetTombstone","tombstone":{"__typename":"TextTombstone","text":{"rtl":false,"text":"This Post was deleted by the Post author. Learn more",` +
+			`"entities":[{"fromIndex":41,"toIndex":51,"ref":{"type":"TimelineUrl","url":"https://help.x.com/rules-and-policies/notices-on-x","urlType":"ExternalUrl"}}]}}}`,
+			helicon.TweetResultTombstone, "tweet is unavailable: This Post was deleted by the Post author. Learn more"},
+		{"unavailable", `{"__typename":"TweetUnavailable","reason":"Suspended"}`, helicon.TweetResultUnavailable, "tweet is unavailable: Suspended"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var result helicon.TweetResult
+			if err := json.Unmarshal([]byte(tt.json), &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Typename != tt.typename {
+				t.Errorf("expected typename %q, got %q", tt.typename, result.Typename)
+			}
+			err := result.Err()
+			if tt.err == "" {
+				if err != nil || result.Tweet == nil || result.Tweet.Legacy.FullText != "hi" {
+					t.Fatalf("expected a tweet, got %+v, %v", result.Tweet, err)
+				}
+			} else if !errors.Is(err, helicon.ErrTweetUnavailable) || err.Error() != tt.err {
+				t.Fatalf("expected %q, got %v", tt.err, err)
+			}
+
+			marshalled, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var again helicon.TweetResult
+			if err := json.Unmarshal(marshalled, &again); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, again) {
+				t.Fatalf("result changed after marshalling\n%+v\n%+v", result, again)
+			}
+		})
+	}
+}
+
+func TestTweetResult_Visibility(t *testing.T) {
+	t.Parallel()
+	var result helicon.TweetResult
+	err := json.Unmarshal([]byte(`{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"1"},`+
+		`"limitedActionResults":{"limited_actions":[{"action":"Reply","prompt":{"headline":{"text":"Replies are limited"}}}]},`+
+		`"tweetInterstitial":{"displayType":"EntireTweet","text":{"text":"This Post violated the X Rules."}}}`), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.LimitedActions) != 1 || result.LimitedActions[0].Action != "Reply" || result.LimitedActions[0].Prompt.Headline.Text != "Replies are limited" {
+		t.Errorf("unexpected limited actions %+v", result.LimitedActions)
+	}
+	if result.Interstitial == nil || result.Interstitial.Text.Text != "This Post violated the X Rules." {
+		t.Errorf("unexpected interstitial %+v", result.Interstitial)
+	}
+}
+
+func TestTweetDetailResponse_TweetResults(t *testing.T) {
+	t.Parallel()
+	data, err := os.ReadFile("testdata/tweet_detail.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response helicon.TweetDetailResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatal(err)
+	}
+	entries := response.Data.ThreadedConversationWithInjectionsV2.Instructions[1].Entries
+	// focal tweet is wrapped in TweetWithVisibilityResults, replies are plain tweets, both end up in Tweet.
+	focal := entries[0].Content.ItemContent.TweetResults.Result
+	if focal.Typename != helicon.TweetResultWithVisibility || focal.Tweet.Legacy.IdStr != "1800000000000000001" || len(focal.LimitedActions) != 1 {
+		t.Errorf("unexpected focal tweet %+v", focal)
+	}
+	reply := entries[1].Content.Items[0].Item.ItemContent.TweetResults.Result
+	if reply.Typename != helicon.TweetResultTweet || reply.Tweet.Legacy.IdStr != "1800000000000000002" || reply.Tweet.Core.UserResults.Result.Legacy.ScreenName != "bob" {
+		t.Errorf("unexpected reply %+v", reply)
+	}
+}
+
+func TestExtractTweets_Unavailable(t *testing.T) {
+	t.Parallel()
+	set, err := helicon.ExtractTweets([]byte(`{"entries":[
+		{"tweet_results":{"result":{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post is from a suspended account."}}}}},
+		{"tweet_results":{"result":{"__typename":"TweetUnavailable","reason":"Protected"}}},
+		{"tweet_results":{}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Tweets) != 0 || len(set.Unavailable) != 2 {
+		t.Fatalf("expected 2 unavailable tweets, got %d tweets and %d unavailable", len(set.Tweets), len(set.Unavailable))
+	}
+	if set.Unavailable[1].Unavailable.Reason != helicon.UnavailableProtected {
+		t.Errorf("unexpected reason %q", set.Unavailable[1].Unavailable.Reason)
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -21,130 +21,58 @@ import (
 //
 //	https://abs.twimg.com/responsive-web/client-web-legacy/main.175fd69a.js
 func (h *Helicon) FindTwitterMainJavascriptUrl() (*string, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", "https://x.com/i/flow/login/", nil)
+	page, err := h.fetchLoginPage()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", "https://x.com/i/flow/login/", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", h.UserAgent)
-	//goland:noinspection GoLinter
-	resp, err := client.Do(req)
+	scriptUri, err := findMainJavascriptUrl(page.html)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.String(), err)
+		return nil, err
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.Error("failed to close request", "error", err)
-		}
-	}(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch %s, status: %s", resp.Request.URL.String(), resp.Status)
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from %s: %w", req.URL.String(), err)
-	}
-	htmlContent := string(body)
-	var re *regexp.Regexp
-	var matches []string
-	re = regexp.MustCompile(`src=["'](https://abs\.twimg\.com/responsive-web/client-web-legacy/main\.[\w.-]+\.js)["']`)
-	matches = re.FindStringSubmatch(htmlContent)
-	if len(matches) == 0 {
-		re = regexp.MustCompile(`src=["'](https://abs\.twimg\.com/responsive-web/client-web/main\.[\w.-]+\.js)["']`)
-		matches = re.FindStringSubmatch(htmlContent)
-		if len(matches) == 0 {
-			return nil, fmt.Errorf("failed to locate main script source inside html")
-		}
-	}
-	var scriptUri string
-	_, scriptUri, _ = strings.Cut(re.FindStringSubmatch(htmlContent)[0], "src=")
-	scriptUri = strings.TrimPrefix(scriptUri, `"`)
-	scriptUri = strings.TrimSuffix(scriptUri, `"`)
 	return &scriptUri, nil
 }
 
 // FindAnonymousBearerToken finds and returns the preset Bearer token inside main javascript url.
 // Every main JS contains a Bearer token that is used in login flows, this function finds and returns that token.
 //
-// See [Helicon.FindTwitterMainJavascriptUrl] for `mainJavascriptUrl` parameter.
+// main.js is only downloaded when it changed since the last call, see [Helicon.Bootstrap] and [BootstrapCache]. No guest
+// token is looked for or activated.
 //
 // Returned value is ready to use token, with `Bearer ` prefix included, just use it like
 //
 //	request.Header.Set("Authorization", anonymousToken)
 func (h *Helicon) FindAnonymousBearerToken() (*string, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
-	mainScriptUrl, err := h.FindTwitterMainJavascriptUrl()
+	page, err := h.fetchLoginPage()
 	if err != nil {
-		return nil, fmt.Errorf("failed to find main script url of Twitter")
+		return nil, err
 	}
-	req, err := http.NewRequest("GET", *mainScriptUrl, nil)
+	bootstrap, err := h.bootstrapBearer(page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", *mainScriptUrl, err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", h.UserAgent)
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.String(), err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.Error("failed to close request", "error", err)
-		}
-	}(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch %s, status: %s", resp.Request.URL.String(), resp.Status)
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from %s: %w", req.URL.String(), err)
-	}
-	var re *regexp.Regexp
-	var matches [][]byte
-	var match string
-	re = regexp.MustCompile("(Bearer)(.*?)(\"|\\z)")
-	matches = re.FindAll(body, -1)
-	match = string(matches[len(matches)-1])
-	match = strings.TrimPrefix(match, `"`)
-	match = strings.TrimSuffix(match, `"`)
-	return &match, nil
+	return &bootstrap.BearerToken, nil
 }
 
 // GenerateGuestToken doesnt actually generate anything, it just requests the login page and gets the guest ID.
+// If the page does not have one, it is activated through guest/activate.json, main.js is only needed for that.
 // Take this ID, put it into header `x-guest-token` where needed in login flow.
 func (h *Helicon) GenerateGuestToken() (*string, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", "https://x.com/i/flow/login/", nil)
+	page, err := h.fetchLoginPage()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", "https://x.com/i/flow/login/", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", h.UserAgent)
-	//goland:noinspection GoLinter
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.String(), err)
+	if guestToken, err := findGuestToken(page); err == nil {
+		return &guestToken, nil
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.Error("failed to close request", "error", err)
-		}
-	}(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch %s, status: %s", resp.Request.URL.String(), resp.Status)
+	bootstrap, err := h.bootstrapBearer(page)
+	if err != nil {
+		return nil, err
 	}
-	body, err := io.ReadAll(resp.Body)
+	guestToken, err := h.activateGuestToken(bootstrap.BearerToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from %s: %w", req.URL.String(), err)
-	}
-	htmlContent := string(body)
-	var re *regexp.Regexp
-	var matches []string
-	re = regexp.MustCompile(`document\.cookie="gt=([0-9]+)`)
-	matches = re.FindStringSubmatch(htmlContent)
-	return &matches[1], nil
+		return nil, err
+	}
+	return &guestToken, nil
 }
 
 type LoginFlow struct {
@@ -161,23 +89,24 @@ type LoginFlow struct {
 			} `json:"next_link"`
 		} `json:"js_instrumentation"`
 	} `json:"subtasks"`
-	AnonymousBearerToken string
-	GuestToken           string
-	GuestId              string
-	UserAgent            string
-	Att                  string
-	CFBM                 string
+	AnonymousBearerToken string `json:"anonymous_bearer_token"`
+	GuestToken           string `json:"guest_token"`
+	GuestId              string `json:"guest_id"`
+	UserAgent            string `json:"user_agent"`
+	Att                  string `json:"att"`
+	CFBM                 string `json:"cf_bm"`
+	// subtask X is waiting for, nil if flow is completed. see [LoginFlow.Next].
+	Pending *LoginSubtask `json:"pending,omitempty"`
+	// true once X handed out the session cookies.
+	Completed bool `json:"completed"`
 }
 
 func (h *Helicon) StartLoginFlow() (*LoginFlow, error) {
-	anonymousToken, err := h.FindAnonymousBearerToken()
-	if err != nil {
-		return nil, fmt.Errorf("failed to find anonymous bearer token: %w", err)
-	}
-	guestId, err := h.GenerateGuestToken()
+	bootstrap, err := h.Bootstrap()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate guest token: %w", err)
+		return nil, fmt.Errorf("failed to find anonymous bearer and guest token: %w", err)
 	}
+	anonymousToken, guestId := &bootstrap.BearerToken, &bootstrap.GuestToken
 	body := `{
 	"input_flow_data": {
 		"flow_context": {
@@ -216,6 +145,12 @@ func (h *Helicon) StartLoginFlow() (*LoginFlow, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	var taskResponse onboardingResponse
+	if err := json.Unmarshal(respBytes, &taskResponse); err == nil {
+		if err := taskResponse.blocker(); err != nil {
+			return nil, err
+		}
+	}
 	if resp.StatusCode > 200 {
 		return nil, fmt.Errorf("unexpected status %d from %s, raw body: %s", resp.StatusCode, req.URL.String(), string(respBytes))
 	}
@@ -226,6 +161,9 @@ func (h *Helicon) StartLoginFlow() (*LoginFlow, error) {
 	loginFlow.AnonymousBearerToken = *anonymousToken
 	loginFlow.GuestToken = *guestId
 	loginFlow.UserAgent = h.UserAgent
+	if len(taskResponse.Subtasks) > 0 {
+		loginFlow.Pending = &taskResponse.Subtasks[0]
+	}
 	recvCookieLines := resp.Header.Values("Set-Cookie")
 	for _, cookieLine := range recvCookieLines {
 		for cookie := range strings.SplitSeq(cookieLine, ";") {
@@ -244,11 +182,13 @@ func (h *Helicon) StartLoginFlow() (*LoginFlow, error) {
 	return &loginFlow, nil
 }
 
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitJSChallengeRequest struct {
 	FlowToken     string                                 `json:"flow_token"`
 	SubtaskInputs []SubmitJSChallengeRequestSubtaskInput `json:"subtask_inputs"`
 }
 
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitJSChallengeRequestSubtaskInput struct {
 	SubtaskId         string `json:"subtask_id"`
 	JsInstrumentation struct {
@@ -257,6 +197,7 @@ type SubmitJSChallengeRequestSubtaskInput struct {
 	} `json:"js_instrumentation"`
 }
 
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitJSChallengeResponse struct {
 	FlowToken string `json:"flow_token"`
 	Status    string `json:"status"`
@@ -275,24 +216,19 @@ func (f *LoginFlow) SolveAndSubmitJSChallenge(h *Helicon) error {
 	if err := json.NewDecoder(strings.NewReader(*challengeSolution)).Decode(&unMarshalledChallengeSolution); err != nil {
 		return fmt.Errorf("failed to decode challenge solution, %w. raw body: %s", err, *challengeSolution)
 	}
-	var requestBody SubmitJSChallengeRequest
-	requestBody.FlowToken = f.FlowToken
-	requestBody.SubtaskInputs = []SubmitJSChallengeRequestSubtaskInput{
-		{
-			SubtaskId: "LoginJsInstrumentationSubtask",
-			JsInstrumentation: struct {
-				Response string `json:"response"`
-				Link     string `json:"link"`
-			}{Response: *challengeSolution, Link: "next_link"},
-		},
-	}
+	return f.submitSubtask(h, SubtaskJsInstrumentation, *challengeSolution)
+}
+
+// postTask sends subtask inputs to onboarding/task.json with guest headers and cookies of this flow.
+// X errors and subtasks that we can't continue from are returned as typed errors, see [LoginBlockedError].
+func (f *LoginFlow) postTask(body any) (*onboardingResponse, *http.Response, error) {
 	var bodyMarshalled = bytes.NewBuffer(nil)
-	if err := json.NewEncoder(bodyMarshalled).Encode(requestBody); err != nil {
-		return fmt.Errorf("failed to encode request body: %w", err)
+	if err := json.NewEncoder(bodyMarshalled).Encode(body); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode request body: %w", err)
 	}
 	req, err := http.NewRequest(http.MethodPost, "https://api.x.com/1.1/onboarding/task.json", bodyMarshalled)
 	if err != nil {
-		return fmt.Errorf("could not construct request: %w", err)
+		return nil, nil, fmt.Errorf("could not construct request: %w", err)
 	}
 	req.Header.Set("Authorization", f.AnonymousBearerToken)
 	req.Header.Set("User-Agent", f.UserAgent)
@@ -303,12 +239,14 @@ func (f *LoginFlow) SolveAndSubmitJSChallenge(h *Helicon) error {
 	cookieHeader = fmt.Sprintf("gt=%s", f.GuestToken)
 	cookieHeader = fmt.Sprintf("%s; att=%s", cookieHeader, f.Att)
 	cookieHeader = fmt.Sprintf("%s; guest_id_ads=%s; guest_id_marketing=%s; guest_id=%s", cookieHeader, f.GuestId, f.GuestId, f.GuestId)
-	cookieHeader = fmt.Sprintf("%s; __cf_bm=%s", cookieHeader, f.CFBM)
+	if f.CFBM != "" {
+		cookieHeader = fmt.Sprintf("%s; __cf_bm=%s", cookieHeader, f.CFBM)
+	}
 	req.Header.Set("Cookie", cookieHeader)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute POST: %w", err)
+		return nil, nil, fmt.Errorf("failed to execute POST: %w", err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -318,21 +256,29 @@ func (f *LoginFlow) SolveAndSubmitJSChallenge(h *Helicon) error {
 	}(resp.Body)
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read request body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	var taskResponse onboardingResponse
+	decodeErr := json.Unmarshal(respBytes, &taskResponse)
+	if decodeErr == nil {
+		if err := taskResponse.blocker(); err != nil {
+			return nil, resp, err
+		}
 	}
 	if resp.StatusCode > 200 {
-		return fmt.Errorf("unexpected status code %d from %s, raw body: %s", resp.StatusCode, resp.Request.URL.String(), string(respBytes))
+		return nil, resp, fmt.Errorf("unexpected status code %d from %s, raw body: %s", resp.StatusCode, resp.Request.URL.String(), string(respBytes))
 	}
-	var challengeResponse SubmitJSChallengeResponse
-	if err := json.NewDecoder(bytes.NewReader(respBytes)).Decode(&challengeResponse); err != nil {
-		return fmt.Errorf("failed to decode response, %w", err)
+	if decodeErr != nil {
+		return nil, resp, fmt.Errorf("failed to decode response, %w", decodeErr)
 	}
-	f.FlowToken = challengeResponse.FlowToken
-	return nil
+	return &taskResponse, resp, nil
 }
 
 func (f *LoginFlow) solveJSInstrumentationChallenge(userAgent string) (*string, error) {
-	target := f.Subtasks[0].JsInstrumentation.Url
+	target := f.jsInstrumentationUrl()
+	if target == "" {
+		return nil, fmt.Errorf("flow has no js instrumentation subtask")
+	}
 	resp, err := http.Get(target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hit %s: %w", target, err)
@@ -443,6 +389,7 @@ func (f *LoginFlow) solveJSInstrumentationChallenge(userAgent string) (*string,
 	return &resultStr, nil
 }
 
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitUsernameRequest struct {
 	FlowToken     string          `json:"flow_token"`
 	SubtaskInputs []SubtaskInputs `json:"subtask_inputs"`
@@ -461,17 +408,21 @@ type SettingsList struct {
 	SettingResponses []SettingResponses `json:"setting_responses"`
 	Link             string             `json:"link"`
 }
+
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubtaskInputs struct {
 	SubtaskID    string       `json:"subtask_id"`
 	SettingsList SettingsList `json:"settings_list"`
 }
 
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitUsernameResponse struct {
 	FlowToken string `json:"flow_token"`
 	Status    string `json:"status"`
 	// we do not care about any of the subtasks, only flow token, not even status.
 }
 
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitPasswordRequest struct {
 	FlowToken     string                       `json:"flow_token"`
 	SubtaskInputs []SubmitPasswordSubtaskInput `json:"subtask_inputs"`
@@ -480,133 +431,55 @@ type EnterPassword struct {
 	Password string `json:"password"`
 	Link     string `json:"link"`
 }
+
+// Deprecated: login requests are built by [LoginFlow.Submit], this is not used anymore.
 type SubmitPasswordSubtaskInput struct {
 	SubtaskID     string        `json:"subtask_id"`
 	EnterPassword EnterPassword `json:"enter_password"`
 }
 
+// SubmitUsernameAndPassword submits credentials of helicon into the flow, and on success, fills the session cookies.
+//
+// Returns one of the [LoginBlockedError] types if X stops the flow (arkose, locked account, wrong password, etc.),
+// [VerificationRequiredError] if X wants an email, phone number or 2FA code (drive the flow with [LoginFlow.Next] and
+// [LoginFlow.Submit] for these),
+// and [ErrLoginNotCompleted] if everything went through but X did not hand out the cookies.
 func (f *LoginFlow) SubmitUsernameAndPassword(helicon *Helicon) error {
-	var submitUsernameBody SubmitUsernameRequest
-	submitUsernameBody.FlowToken = f.FlowToken
-	submitUsernameBody.SubtaskInputs = append(submitUsernameBody.SubtaskInputs, SubtaskInputs{
-		SubtaskID: "LoginEnterUserIdentifierSSO",
-		SettingsList: SettingsList{
-			Link: "next_link",
-			SettingResponses: []SettingResponses{
-				{
-					Key: "user_identifier",
-					ResponseData: ResponseData{
-						TextData: TextData{
-							Result: helicon.Credentials.Username,
-						},
-					},
-				},
-			},
-		},
-	})
-	var bodyMarshalled = bytes.NewBuffer(nil)
-	if err := json.NewEncoder(bodyMarshalled).Encode(submitUsernameBody); err != nil {
-		return fmt.Errorf("failed to marshal request submitUsernameBody: %w", err)
-	}
-	req, err := http.NewRequest(http.MethodPost, "https://api.x.com/1.1/onboarding/task.json", bodyMarshalled)
-	if err != nil {
-		return fmt.Errorf("could not construct request: %w", err)
-	}
-	req.Header.Set("Authorization", f.AnonymousBearerToken)
-	req.Header.Set("User-Agent", f.UserAgent)
-	req.Header.Set("x-twitter-active-user", "yes")
-	req.Header.Set("x-twitter-client-language", "en")
-	req.Header.Set("x-guest-token", f.GuestToken)
-	var cookieHeader string
-	cookieHeader = fmt.Sprintf("gt=%s", f.GuestToken)
-	cookieHeader = fmt.Sprintf("%s; att=%s", cookieHeader, f.Att)
-	cookieHeader = fmt.Sprintf("%s; guest_id_ads=%s; guest_id_marketing=%s; guest_id=%s", cookieHeader, f.GuestId, f.GuestId, f.GuestId)
-	req.Header.Set("Cookie", cookieHeader)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute POST: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.Error("failed to close request body", "error", err)
-		}
-	}(resp.Body)
-	respBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read request body: %w", err)
-	}
-	if resp.StatusCode > 200 {
-		return fmt.Errorf("unexpected status code %d from %s, raw body: %s", resp.StatusCode, resp.Request.URL.String(), string(respBytes))
-	}
-	var submitUsernameResponse SubmitUsernameResponse
-	if err := json.NewDecoder(bytes.NewReader(respBytes)).Decode(&submitUsernameResponse); err != nil {
-		return fmt.Errorf("failed to decode response, %w", err)
-	}
-	var submitPasswordRequest SubmitPasswordRequest
-	submitPasswordRequest.FlowToken = submitUsernameResponse.FlowToken
-	submitPasswordRequest.SubtaskInputs = []SubmitPasswordSubtaskInput{
-		{
-			EnterPassword: EnterPassword{
-				Link:     "next_link",
-				Password: helicon.Credentials.Password,
-			},
-			SubtaskID: "LoginEnterPassword",
-		},
-	}
-	bodyMarshalled = bytes.NewBuffer(nil)
-	if err := json.NewEncoder(bodyMarshalled).Encode(submitPasswordRequest); err != nil {
-		return fmt.Errorf("failed to marshal request submitUsernameBody: %w", err)
-	}
-	req, err = http.NewRequest(http.MethodPost, "https://api.x.com/1.1/onboarding/task.json", bodyMarshalled)
-	if err != nil {
-		return fmt.Errorf("could not construct request: %w", err)
-	}
-	req.Header.Set("Authorization", f.AnonymousBearerToken)
-	req.Header.Set("User-Agent", f.UserAgent)
-	req.Header.Set("x-twitter-active-user", "yes")
-	req.Header.Set("x-twitter-client-language", "en")
-	req.Header.Set("x-guest-token", f.GuestToken)
-	cookieHeader = fmt.Sprintf("gt=%s", f.GuestToken)
-	cookieHeader = fmt.Sprintf("%s; att=%s", cookieHeader, f.Att)
-	cookieHeader = fmt.Sprintf("%s; guest_id_ads=%s; guest_id_marketing=%s; guest_id=%s", cookieHeader, f.GuestId, f.GuestId, f.GuestId)
-	req.Header.Set("Cookie", cookieHeader)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err = http.DefaultClient.Do(req)
+	// subtasks are answered in the order X asks for them, it can put another step between username and password.
+	pending, err := f.Next(helicon)
 	if err != nil {
-		return fmt.Errorf("failed to execute POST: %w", err)
+		return err
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.Error("failed to close request body", "error", err)
-		}
-	}(resp.Body)
-	respBytes, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read request body: %w", err)
-	}
-	if resp.StatusCode > 200 {
-		return fmt.Errorf("unexpected status code %d from %s, raw body: %s", resp.StatusCode, resp.Request.URL.String(), string(respBytes))
+	switch {
+	case pending == nil:
+		return nil
+	case pending.NeedsInput():
+		return &VerificationRequiredError{LoginBlockedError{SubtaskID: pending.SubtaskID, Message: pending.Message()}}
+	default:
+		return fmt.Errorf("login flow stopped at subtask %s, answer it with LoginFlow.Submit", pending.SubtaskID)
 	}
+}
+
+// setSessionCookies picks up ct0, auth_token and twid from a response that completes the login.
+// returns false if response did not have both ct0 and auth_token.
+func (h *Helicon) setSessionCookies(resp *http.Response) bool {
+	var gotCSRF, gotAuth bool
 	for _, rawCookie := range resp.Header.Values("Set-Cookie") {
 		rawCookieSplit := strings.Split(rawCookie, ";")
 		k, _, _ := strings.Cut(rawCookieSplit[0], "=")
 		k = strings.TrimSpace(k)
 		switch k {
 		case "ct0":
-			helicon.Cookies.CSRFToken.Raw = rawCookie
+			h.Cookies.CSRFToken.Raw = rawCookie
+			gotCSRF = true
 		case "auth_token":
-			helicon.Cookies.AuthToken.Raw = rawCookie
-		}
-	}
-	if helicon.Cookies.CSRFToken.Raw == "" {
-		slog.Info("we have to execute another login flow, current attempt was successful but logged us out...")
-		err = helicon.Login()
-		if err != nil {
-			return err
+			h.Cookies.AuthToken.Raw = rawCookie
+			gotAuth = true
+		case "twid":
+			_, v, _ := strings.Cut(rawCookieSplit[0], "=")
+			v, _ = url.QueryUnescape(strings.Trim(v, `"`))
+			h.UserID = strings.TrimPrefix(v, "u=")
 		}
 	}
-	return nil
+	return gotCSRF && gotAuth
 }